@@ -0,0 +1,254 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// calendar versioning, see https://calver.org/
+
+// Clock returns the current time, and is used to decide the date of a new calendar version.
+type Clock func() time.Time
+
+func isCalendar(field Field) bool {
+	switch field {
+	case year, short_year, month, week, day:
+		return true
+	default:
+		return false
+	}
+}
+
+// slotOf returns the counter field a field is stored in.
+func slotOf(field Field) Field {
+	switch field {
+	case alphabetic_build:
+		return build
+	case alphabetic_patch, day:
+		return patch
+	case month, week:
+		return minor
+	case year, short_year:
+		return major
+	default:
+		return field
+	}
+}
+
+func layoutFields(layout string) ([]Field, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return nil, err
+	}
+	return l.fields(), nil
+}
+
+// isoWeeksInYear returns the count of ISO weeks in a year,
+// taken from a year in the 400 years the Gregorian calendar repeats in, so that any year read is in range of time.Date.
+func isoWeeksInYear(year int64) int64 {
+	_, weeks := time.Date(2000+int(year%400), time.December, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+	return int64(weeks)
+}
+
+func isLeapYear(year int64) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func daysInMonth(year int64, month int64) int64 {
+	switch month {
+	case 2:
+		if isLeapYear(year) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	default:
+		return 31
+	}
+}
+
+func validateCalendar(v *Version, calendar fieldSet) error {
+	hasYear := calendar.has(year) || calendar.has(short_year)
+	if calendar.has(month) && calendar.has(day) {
+		y := v.Major
+		if !hasYear {
+			y = 2000 // a leap year, allowing 02-29
+		}
+		if v.Patch > daysInMonth(y, v.Minor) {
+			return fmt.Errorf("day out of range: %d-%02d-%02d", y, v.Minor, v.Patch)
+		}
+	}
+	if calendar.has(week) && hasYear && v.Minor > isoWeeksInYear(v.Major) {
+		return fmt.Errorf("week out of range: %d-W%02d", v.Major, v.Minor)
+	}
+	return nil
+}
+
+// ToTime returns the first moment, in UTC, of the date represented by a calendar version under the layout.
+// Fields absent from the layout default to the start of the year, the month or the week.
+func ToTime(layout string, v *Version) (time.Time, error) {
+	fields, err := layoutFields(layout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var calendar fieldSet
+	for _, field := range fields {
+		if isCalendar(field) {
+			calendar = calendar.with(field)
+		}
+	}
+	if !calendar.has(year) && !calendar.has(short_year) {
+		return time.Time{}, errors.New("layout has no year")
+	}
+	if err := validateCalendar(v, calendar); err != nil {
+		return time.Time{}, err
+	}
+	switch {
+	case calendar.has(week):
+		if v.Minor < 1 || v.Minor > 53 {
+			return time.Time{}, fmt.Errorf("week out of range: %d", v.Minor)
+		}
+		jan4 := time.Date(int(v.Major), time.January, 4, 0, 0, 0, 0, time.UTC)
+		firstMonday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+		return firstMonday.AddDate(0, 0, int(v.Minor-1)*7), nil
+	case calendar.has(month):
+		if v.Minor < 1 || v.Minor > 12 {
+			return time.Time{}, fmt.Errorf("month out of range: %d", v.Minor)
+		}
+		d := int64(1)
+		if calendar.has(day) {
+			if v.Patch < 1 || v.Patch > daysInMonth(v.Major, v.Minor) {
+				return time.Time{}, fmt.Errorf("day out of range: %d", v.Patch)
+			}
+			d = v.Patch
+		}
+		return time.Date(int(v.Major), time.Month(v.Minor), int(d), 0, 0, 0, 0, time.UTC), nil
+	case calendar.has(day):
+		return time.Time{}, errors.New("layout has a day but no month")
+	default:
+		return time.Date(int(v.Major), time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+}
+
+// FromTime returns the calendar version of the date of t under the layout, leaving other fields zero.
+// The year is the ISO 8601 week-numbering year if the layout contains a week.
+func FromTime(layout string, t time.Time) (*Version, error) {
+	fields, err := layoutFields(layout)
+	if err != nil {
+		return nil, err
+	}
+	v := &Version{}
+	hasYear := false
+	for _, field := range fields {
+		switch field {
+		case year, short_year:
+			hasYear = true
+		case month:
+			v.Minor = int64(t.Month())
+		case day:
+			v.Patch = int64(t.Day())
+		}
+	}
+	if !hasYear {
+		return nil, errors.New("layout has no year")
+	}
+	v.Major = int64(t.Year())
+	for _, field := range fields {
+		if field == week {
+			isoYear, isoWeek := t.ISOWeek()
+			v.Major, v.Minor = int64(isoYear), int64(isoWeek)
+		}
+	}
+	for _, field := range fields {
+		if field == short_year && v.Major < 2000 {
+			return nil, fmt.Errorf("year not representable as a short year: %d", v.Major)
+		}
+	}
+	return v, nil
+}
+
+// BumpCalendar returns the next calendar version under the layout, following the latest existing version.
+//
+// The date is taken from the clock, or time.Now if the clock is nil.
+// If the latest version is of the same date, the most significant counter in the layout that is not a date,
+// e.g. the build in `YYYY.0M.0D.1`, is incremented instead, and less significant counters are reset.
+// It is an error if the latest version is dated after the clock, or it is of the same date and no counter exists.
+func BumpCalendar(layout string, latest *Version, clock Clock) (*Version, error) {
+	if clock == nil {
+		clock = time.Now
+	}
+	next, err := FromTime(layout, clock())
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return next, nil
+	}
+
+	fields, err := layoutFields(layout)
+	if err != nil {
+		return nil, err
+	}
+	dated := make(map[Field]bool)
+	for _, field := range fields {
+		if isCalendar(field) {
+			dated[slotOf(field)] = true
+		}
+	}
+	dateOf := func(v *Version) *Version {
+		date := &Version{}
+		for slot := range dated {
+			slot.SetField(date, slot.value(v))
+		}
+		return date
+	}
+	if dateOf(next).LT(dateOf(latest)) {
+		return nil, fmt.Errorf("latest version is dated after the clock: %+v", latest)
+	}
+	if !dateOf(latest).EQ(dateOf(next)) {
+		return next, nil
+	}
+
+	counter := Field(0)
+	for _, field := range fields {
+		slot := slotOf(field)
+		switch slot {
+		case build, patch, minor, major:
+			if !dated[slot] && slot > counter {
+				counter = slot
+			}
+		}
+	}
+	if counter == 0 {
+		return nil, fmt.Errorf("no counter to bump in layout %s", layout)
+	}
+	bumped := *latest
+	bumped.Other = ""
+	counter.SetField(&bumped, counter.value(latest)+1)
+	for slot := counter - 1; slot >= build; slot-- {
+		if !dated[slot] {
+			slot.SetField(&bumped, 0)
+		}
+	}
+	return &bumped, nil
+}
@@ -0,0 +1,144 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gsxab/go-version"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestTime(t *testing.T) {
+	cases := []struct {
+		Layout  string
+		Version *version.Version
+		Time    time.Time
+	}{
+		{
+			"YYYY.0M.0D",
+			&version.Version{Major: 2023, Minor: 12, Patch: 30},
+			date(2023, time.December, 30),
+		},
+		{
+			"YY.0M",
+			&version.Version{Major: 2023, Minor: 4},
+			date(2023, time.April, 1),
+		},
+		{
+			"YYYY.W0W",
+			&version.Version{Major: 2024, Minor: 7},
+			date(2024, time.February, 12),
+		},
+		{
+			"YYYY.W0W",
+			&version.Version{Major: 2021, Minor: 1},
+			date(2021, time.January, 4),
+		},
+	}
+
+	for _, c := range cases {
+		tm, err := version.ToTime(c.Layout, c.Version)
+		if err != nil || !tm.Equal(c.Time) {
+			t.Errorf("ToTime failed, expected: %v, actual: %v, %v; layout: %v, input: %+v", c.Time, tm, err, c.Layout, c.Version)
+		}
+		v, err := version.FromTime(c.Layout, c.Time)
+		if err != nil || !v.EQ(c.Version) {
+			t.Errorf("FromTime failed, expected: %+v, actual: %+v, %v; layout: %v, input: %v", c.Version, v, err, c.Layout, c.Time)
+		}
+	}
+
+	if _, err := version.ToTime("YYYY.0M.0D", &version.Version{Major: 2023, Minor: 2, Patch: 29}); err == nil {
+		t.Errorf("ToTime accepted an invalid date")
+	}
+	if _, err := version.FromTime("5.4.3", date(2023, time.December, 30)); err == nil {
+		t.Errorf("FromTime accepted a layout without a year")
+	}
+	// 2021-01-03 belongs to the last week of 2020
+	v, err := version.FromTime("YYYY.0W", date(2021, time.January, 3))
+	if err != nil || !v.EQ(&version.Version{Major: 2020, Minor: 53}) {
+		t.Errorf("FromTime did not use the week-numbering year, actual: %+v, %v", v, err)
+	}
+}
+
+func TestBumpCalendar(t *testing.T) {
+	format := "YYYY.0M.0D$.1"
+	clock := func() time.Time {
+		return date(2023, time.December, 30)
+	}
+
+	cases := []struct {
+		Latest   *version.Version
+		Expected string
+		RaiseErr bool
+	}{
+		{
+			nil,
+			"2023.12.30",
+			false,
+		},
+		{
+			&version.Version{Major: 2023, Minor: 12, Patch: 29, Build: 3},
+			"2023.12.30",
+			false,
+		},
+		{
+			&version.Version{Major: 2023, Minor: 12, Patch: 30},
+			"2023.12.30.1",
+			false,
+		},
+		{
+			&version.Version{Major: 2023, Minor: 12, Patch: 30, Build: 1},
+			"2023.12.30.2",
+			false,
+		},
+		{
+			&version.Version{Major: 2023, Minor: 12, Patch: 31},
+			"",
+			true,
+		},
+	}
+
+	for _, c := range cases {
+		v, err := version.BumpCalendar(format, c.Latest, clock)
+		if c.RaiseErr != (err != nil) {
+			t.Errorf("error expectation failed, expected: %v, actual: %+v; latest: %+v", c.RaiseErr, err, c.Latest)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		s, _ := version.Format(format, v)
+		if s != c.Expected {
+			t.Errorf("version expectation failed, expected: %v, actual: %v; latest: %+v", c.Expected, s, c.Latest)
+		}
+	}
+
+	v, err := version.BumpCalendar("YY.0M.3", &version.Version{Major: 2023, Minor: 12, Patch: 4}, clock)
+	if err != nil || !v.EQ(&version.Version{Major: 2023, Minor: 12, Patch: 5}) {
+		t.Errorf("BumpCalendar did not bump the patch, actual: %+v, %v", v, err)
+	}
+	if _, err := version.BumpCalendar("YYYY.0M.0D", &version.Version{Major: 2023, Minor: 12, Patch: 30}, clock); err == nil {
+		t.Errorf("BumpCalendar bumped without a counter")
+	}
+}
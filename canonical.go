@@ -0,0 +1,178 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangeKind is the kind of a change made by normalization.
+type ChangeKind int
+
+const (
+	// ChangeAdded is an element written though absent in the input, e.g. the `.0` in `1.2.0` for `1.2`.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved is an element present in the input but not written, e.g. the `v` in `1.2` for `v1.2`.
+	ChangeRemoved
+	// ChangeRewritten is an element written differently, e.g. the `1` in `1.2` for `01.2`.
+	ChangeRewritten
+)
+
+func (kind ChangeKind) String() string {
+	switch kind {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeRewritten:
+		return "rewritten"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(kind))
+	}
+}
+
+// Change is an element of a version string changed by normalization.
+type Change struct {
+	Kind  ChangeKind
+	Field Field  // the field of the element, or a literal
+	From  string // the element in the input, empty if added
+	To    string // the element in the canonical string, empty if removed
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("added %v %q", c.Field, c.To)
+	case ChangeRemoved:
+		return fmt.Sprintf("removed %v %q", c.Field, c.From)
+	default:
+		return fmt.Sprintf("rewritten %v %q as %q", c.Field, c.From, c.To)
+	}
+}
+
+// NormalizationReport explains how a version string is normalized.
+type NormalizationReport struct {
+	Input     string
+	Canonical string
+	Changes   []Change // in the order of the layout
+}
+
+func (r *NormalizationReport) String() string {
+	changes := make([]string, len(r.Changes))
+	for i, change := range r.Changes {
+		changes[i] = change.String()
+	}
+	return fmt.Sprintf("%s normalized as %s: %s", r.Input, r.Canonical, strings.Join(changes, ", "))
+}
+
+// Canonical returns the canonical spelling of a version string in the layout, i.e. the string Format writes for it,
+// e.g. `1.2` for `v1.2` in `[v]5.4$.3`, where the zero patch after `$` is not written either.
+// A group of only literals, like `[v]`, is never written, so the canonical spelling of `v1.2.3` in `[v]5.4.3` is `1.2.3`.
+func Canonical(layout string, versionString string) (string, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return "", err
+	}
+	return l.Canonical(versionString)
+}
+
+// Equivalent reports whether two version strings are read as the same version in the layout, including the other text.
+func Equivalent(layout string, versionString1 string, versionString2 string) (bool, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return false, err
+	}
+	return l.Equivalent(versionString1, versionString2)
+}
+
+// Normalize returns the canonical spelling of a version string like Canonical,
+// and a report explaining the elements added, removed or rewritten.
+func Normalize(layout string, versionString string) (string, *NormalizationReport, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return "", nil, err
+	}
+	return l.Normalize(versionString)
+}
+
+// Canonical returns the canonical spelling of a version string, like the function Canonical.
+func (l *Layout) Canonical(versionString string) (string, error) {
+	v, err := l.Parse(versionString)
+	if err != nil {
+		return "", err
+	}
+	return l.Format(v), nil
+}
+
+// Equivalent reports whether two version strings are read as the same version, like the function Equivalent.
+func (l *Layout) Equivalent(versionString1 string, versionString2 string) (bool, error) {
+	v1, err := l.Parse(versionString1)
+	if err != nil {
+		return false, err
+	}
+	v2, err := l.Parse(versionString2)
+	if err != nil {
+		return false, err
+	}
+	return v1.EQ(v2) && v1.Other == v2.Other, nil
+}
+
+// Normalize returns the canonical spelling of a version string and a report, like the function Normalize.
+func (l *Layout) Normalize(versionString string) (string, *NormalizationReport, error) {
+	v, trace, err := l.match(versionString, false, true)
+	if err != nil {
+		return "", nil, err
+	}
+	parts, _ := formatNodes(l.nodes, v)
+	canonical := joinSpans(parts)
+
+	read := make(map[*node]string)
+	for s := trace; s != nil; s = s.prev {
+		read[s.node] = s.text
+	}
+	written := make(map[*node]string)
+	for _, part := range parts {
+		written[part.node] = part.text
+	}
+
+	report := &NormalizationReport{Input: versionString, Canonical: canonical, Changes: make([]Change, 0)}
+	var walk func(nodes []node)
+	walk = func(nodes []node) {
+		for i := range nodes {
+			n := &nodes[i]
+			for _, alt := range n.alts {
+				walk(alt)
+			}
+			from, to := read[n], written[n]
+			if from == to {
+				continue
+			}
+			change := Change{Kind: ChangeRewritten, Field: n.field, From: from, To: to}
+			if from == "" {
+				change.Kind = ChangeAdded
+			} else if to == "" {
+				change.Kind = ChangeRemoved
+			}
+			report.Changes = append(report.Changes, change)
+		}
+	}
+	walk(l.nodes)
+	return canonical, report, nil
+}
@@ -0,0 +1,150 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestCanonical(t *testing.T) {
+	cases := []struct {
+		Layout   string
+		Input    string
+		Expected string
+	}{
+		{"[v]5.4$.3", "v1.2", "1.2"},
+		{"[v]5.4$.3", "1.2.0", "1.2"},
+		{"[v]5.4$.3", "v1.2.3", "1.2.3"},
+		{"v5.4.3", "1.2.3", "v1.2.3"},
+		{"5.4.3-beta.1", "1.2.3rc4", "1.2.3-rc.4"},
+		{"05.04.03", "1.2.3", "01.02.03"},
+	}
+
+	for _, c := range cases {
+		s, err := version.Canonical(c.Layout, c.Input)
+		if err != nil {
+			t.Errorf("unexpected error: %v; layout: %v, input: %v", err, c.Layout, c.Input)
+			continue
+		}
+		if s != c.Expected {
+			t.Errorf("version expectation failed, expected: %v, actual: %v; layout: %v, input: %v", c.Expected, s, c.Layout, c.Input)
+		}
+	}
+
+	if _, err := version.Canonical("5.4.3", "1.x"); err == nil {
+		t.Errorf("ill-formed version string accepted")
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	cases := []struct {
+		Layout   string
+		A        string
+		B        string
+		Expected bool
+	}{
+		{"[v]5.4$.3", "v1.2", "1.2.0", true},
+		{"[v]5.4$.3", "v1.2", "1.2.1", false},
+		{"5.4.3-beta.1", "1.2.3rc1", "1.2.3-rc.1", true},
+		{"5.4.3+o", "1.2.3+a", "1.2.3+b", false},
+	}
+
+	for _, c := range cases {
+		eq, err := version.Equivalent(c.Layout, c.A, c.B)
+		if err != nil {
+			t.Errorf("unexpected error: %v; layout: %v, a: %v, b: %v", err, c.Layout, c.A, c.B)
+			continue
+		}
+		if eq != c.Expected {
+			t.Errorf("equivalence expectation failed, expected: %v, actual: %v; layout: %v, a: %v, b: %v", c.Expected, eq, c.Layout, c.A, c.B)
+		}
+	}
+
+	if _, err := version.Equivalent("5.4.3", "1.2.3", "1.x"); err == nil {
+		t.Errorf("ill-formed version string accepted")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		Layout   string
+		Input    string
+		Expected string
+		Changes  []version.Change
+	}{
+		{
+			"[v]5.4$.3",
+			"1.2",
+			"1.2",
+			[]version.Change{},
+		},
+		{
+			"[v]5.4$.3",
+			"v1.2.0",
+			"1.2",
+			[]version.Change{
+				{version.ChangeRemoved, version.Field(0), "v", ""},
+				{version.ChangeRemoved, version.Field(0), ".", ""},
+				{version.ChangeRemoved, version.PatchField, "0", ""},
+			},
+		},
+		{
+			"v5.4.3",
+			"1.02.3",
+			"v1.2.3",
+			[]version.Change{
+				{version.ChangeAdded, version.Field(0), "", "v"},
+				{version.ChangeRewritten, version.MinorField, "02", "2"},
+			},
+		},
+		{
+			"5.4.3-beta.1",
+			"1.2.3rc4",
+			"1.2.3-rc.4",
+			[]version.Change{
+				{version.ChangeRewritten, version.PreRelField, "rc", "-rc"},
+				{version.ChangeAdded, version.Field(0), "", "."},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		s, report, err := version.Normalize(c.Layout, c.Input)
+		if err != nil {
+			t.Errorf("unexpected error: %v; layout: %v, input: %v", err, c.Layout, c.Input)
+			continue
+		}
+		if s != c.Expected || report.Canonical != c.Expected || report.Input != c.Input {
+			t.Errorf("version expectation failed, expected: %v, actual: %v, report: %v; layout: %v, input: %v", c.Expected, s, report, c.Layout, c.Input)
+		}
+		if len(report.Changes) != len(c.Changes) {
+			t.Errorf("changes expectation failed, expected: %v, actual: %v; layout: %v, input: %v", c.Changes, report.Changes, c.Layout, c.Input)
+			continue
+		}
+		for i, change := range c.Changes {
+			actual := report.Changes[i]
+			if actual.Kind != change.Kind || actual.From != change.From || actual.To != change.To ||
+				(change.Field != 0 && actual.Field != change.Field) {
+				t.Errorf("change expectation failed, expected: %v, actual: %v; layout: %v, input: %v", change, actual, c.Layout, c.Input)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+// CompatibilityPolicy decides which versions a consumer built against a version may use.
+type CompatibilityPolicy interface {
+	// CompatibleRange returns the versions compatible with the version, from the version itself.
+	CompatibleRange(v *Version) Interval
+}
+
+// PolicyFunc is a function used as a CompatibilityPolicy.
+type PolicyFunc func(v *Version) Interval
+
+// CompatibleRange calls the function.
+func (f PolicyFunc) CompatibleRange(v *Version) Interval {
+	return f(v)
+}
+
+var (
+	// Caret is the policy of caret requirements in Cargo, where the first non-zero counter of major, minor and patch is kept,
+	// e.g. `1.2.3` is compatible up to `2.0.0`, `0.2.3` up to `0.3.0`, and `0.0.3` up to `0.0.4` only.
+	Caret CompatibilityPolicy = PolicyFunc(caretRange)
+	// Tilde is the policy of tilde requirements in Cargo, where major and minor are kept, e.g. `1.2.3` is compatible up to `1.3.0`.
+	Tilde CompatibilityPolicy = PolicyFunc(tildeRange)
+	// Strict is a policy stricter than Caret, where a minor bump is breaking,
+	// and a patch bump also is for `0.x`, e.g. `1.2.3` is compatible up to `1.3.0`, and `0.2.3` up to `0.2.4`.
+	Strict CompatibilityPolicy = PolicyFunc(strictRange)
+)
+
+// firstOf returns the least version of a release, i.e. its first alpha.
+func firstOf(major int64, minor int64, patch int64) *Version {
+	return &Version{Major: major, Minor: minor, Patch: patch, PreRel: Alpha}
+}
+
+func caretRange(v *Version) Interval {
+	switch {
+	case v.Major != 0:
+		return Interval{Lower: bound(v), Upper: firstOf(v.Major+1, 0, 0)}
+	case v.Minor != 0:
+		return Interval{Lower: bound(v), Upper: firstOf(0, v.Minor+1, 0)}
+	default:
+		return Interval{Lower: bound(v), Upper: firstOf(0, 0, v.Patch+1)}
+	}
+}
+
+func tildeRange(v *Version) Interval {
+	return Interval{Lower: bound(v), Upper: firstOf(v.Major, v.Minor+1, 0)}
+}
+
+func strictRange(v *Version) Interval {
+	if v.Major != 0 {
+		return Interval{Lower: bound(v), Upper: firstOf(v.Major, v.Minor+1, 0)}
+	}
+	return Interval{Lower: bound(v), Upper: firstOf(0, v.Minor, v.Patch+1)}
+}
+
+func policyOrCaret(policy CompatibilityPolicy) CompatibilityPolicy {
+	if policy == nil {
+		return Caret
+	}
+	return policy
+}
+
+// CompatibleRange returns the versions compatible with the version under a policy, or Caret if it is nil.
+//
+// Pre-releases in the range are compatible only if they are of the same major, minor and patch,
+// which is checked by IsCompatible, but is not expressed in the interval.
+func (v *Version) CompatibleRange(policy CompatibilityPolicy) Interval {
+	return policyOrCaret(policy).CompatibleRange(v)
+}
+
+// IsCompatible reports whether a consumer built against the version may use another version under a policy, or Caret if it is nil,
+// i.e. the other version is in the compatible range, and is not a pre-release unless it is of the same major, minor and patch.
+func (v *Version) IsCompatible(v2 *Version, policy CompatibilityPolicy) bool {
+	if !v.CompatibleRange(policy).Contains(v2) {
+		return false
+	}
+	return v2.PreRel == Release || v2.Major == v.Major && v2.Minor == v.Minor && v2.Patch == v.Patch
+}
+
+// IsBreaking reports whether upgrading from the version to a greater version breaks consumers under a policy, or Caret if it is nil.
+// A version not greater is not an upgrade, and not breaking.
+func (v *Version) IsBreaking(v2 *Version, policy CompatibilityPolicy) bool {
+	return v.LT(v2) && !v.IsCompatible(v2, policy)
+}
@@ -0,0 +1,90 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestIsCompatible(t *testing.T) {
+	const layout = "5.4.3[-beta.1]"
+	cases := []struct {
+		Policy     version.CompatibilityPolicy
+		Built      string
+		Used       string
+		Compatible bool
+		Breaking   bool
+	}{
+		{nil, "1.2.3", "1.2.3", true, false},
+		{nil, "1.2.3", "1.9.0", true, false},
+		{nil, "1.2.3", "2.0.0", false, true},
+		{nil, "1.2.3", "2.0.0-alpha.1", false, true},
+		{nil, "1.2.3", "1.2.2", false, false},
+		{nil, "0.2.3", "0.2.9", true, false},
+		{nil, "0.2.3", "0.3.0", false, true},
+		{nil, "0.0.3", "0.0.4", false, true},
+		{version.Caret, "1.2.3", "1.3.0-beta.1", false, true},
+		{version.Caret, "1.2.3-beta.1", "1.2.3-rc.1", true, false},
+		{version.Caret, "1.2.3-beta.1", "1.2.3", true, false},
+		{version.Caret, "1.2.3-beta.1", "1.4.0", true, false},
+		{version.Tilde, "1.2.3", "1.2.9", true, false},
+		{version.Tilde, "1.2.3", "1.3.0", false, true},
+		{version.Tilde, "0.2.3", "0.2.9", true, false},
+		{version.Strict, "1.2.3", "1.2.9", true, false},
+		{version.Strict, "1.2.3", "1.3.0", false, true},
+		{version.Strict, "0.2.3", "0.2.4", false, true},
+		{version.Strict, "0.2.3", "0.2.3-beta.1", false, false},
+	}
+
+	for _, c := range cases {
+		built, used := mustParse(t, layout, c.Built), mustParse(t, layout, c.Used)
+		if actual := built.IsCompatible(used, c.Policy); actual != c.Compatible {
+			t.Errorf("compatibility expectation failed, expected: %v, actual: %v; built: %v, used: %v", c.Compatible, actual, c.Built, c.Used)
+		}
+		if actual := built.IsBreaking(used, c.Policy); actual != c.Breaking {
+			t.Errorf("breaking expectation failed, expected: %v, actual: %v; built: %v, used: %v", c.Breaking, actual, c.Built, c.Used)
+		}
+	}
+}
+
+func TestCompatibleRange(t *testing.T) {
+	cases := []struct {
+		Policy   version.CompatibilityPolicy
+		Version  string
+		Expected string
+	}{
+		{version.Caret, "1.2.3", ">=1.2.3, <2.0.0-alpha"},
+		{version.Caret, "0.2.3", ">=0.2.3, <0.3.0-alpha"},
+		{version.Caret, "0.0.3", ">=0.0.3, <0.0.4-alpha"},
+		{version.Tilde, "1.2.3-beta.1", ">=1.2.3-beta.1, <1.3.0-alpha"},
+		{version.Strict, "0.2.3", ">=0.2.3, <0.2.4-alpha"},
+		{version.PolicyFunc(func(v *version.Version) version.Interval {
+			return version.Interval{Lower: v}
+		}), "1.2.3", ">=1.2.3"},
+	}
+
+	for _, c := range cases {
+		v := mustParse(t, "5.4.3[-beta.1]", c.Version)
+		if actual := v.CompatibleRange(c.Policy).String(); actual != c.Expected {
+			t.Errorf("range expectation failed, expected: %v, actual: %v; version: %v", c.Expected, actual, c.Version)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a set of versions, e.g. `>=1.2, <2 || >=3` read by ParseConstraint.
+// It is kept as sorted disjoint intervals, so constraints can be combined like sets.
+type Constraint struct {
+	source    string
+	intervals []Interval
+}
+
+// ConstraintError tells why a constraint expression cannot be read.
+type ConstraintError struct {
+	Expr   string
+	Term   string // the comparison failed, or empty if the expression is ill-formed as a whole
+	Reason string
+	Err    error // the error reading the version, if any
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Term == "" {
+		return fmt.Sprintf("constraint %s: %s", e.Expr, e.Reason)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("constraint %s: %s in %s: %v", e.Expr, e.Reason, e.Term, e.Err)
+	}
+	return fmt.Sprintf("constraint %s: %s in %s", e.Expr, e.Reason, e.Term)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// comparisonOps are the operators of comparisons, where the longer ones go first.
+var comparisonOps = []string{">=", "<=", "!=", "==", ">", "<", "=", "^", "~"}
+
+// ParseConstraint reads a constraint expression, where versions are read in the layout.
+//
+// A comparison is an operator followed by a version, i.e. `=`, `==`, `!=`, `<`, `<=`, `>` or `>=`,
+// or `^` and `~` for the compatible range of the version under Caret and Tilde,
+// and a version alone is the same as `=`.
+// Comparisons separated by commas must all hold, and groups of them separated by `||` are alternatives,
+// e.g. `>=1.2, <2 || >=3` in `5$.4$.3`.
+func ParseConstraint(layout string, expr string) (*Constraint, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return nil, err
+	}
+	return l.ParseConstraint(expr)
+}
+
+// ParseConstraint reads a constraint expression, like the function ParseConstraint.
+func (l *Layout) ParseConstraint(expr string) (*Constraint, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, &ConstraintError{Expr: expr, Reason: "empty expression"}
+	}
+	result := &Constraint{}
+	for _, alternative := range strings.Split(expr, "||") {
+		conjunction := AnyVersion()
+		for _, term := range strings.Split(alternative, ",") {
+			c, err := l.parseComparison(expr, strings.TrimSpace(term))
+			if err != nil {
+				return nil, err
+			}
+			conjunction = conjunction.Intersect(c)
+		}
+		result = result.Union(conjunction)
+	}
+	result.source = expr
+	return result, nil
+}
+
+func (l *Layout) parseComparison(expr string, term string) (*Constraint, error) {
+	if term == "" {
+		return nil, &ConstraintError{Expr: expr, Reason: "empty comparison"}
+	}
+	op := ""
+	for _, candidate := range comparisonOps {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	versionString := strings.TrimSpace(term[len(op):])
+	v, err := l.Parse(versionString)
+	if err != nil {
+		return nil, &ConstraintError{Expr: expr, Term: term, Reason: "version not readable", Err: err}
+	}
+	v = bound(v)
+	switch op {
+	case "", "=", "==":
+		return NewConstraint(Point(v)), nil
+	case "!=":
+		return NewConstraint(Point(v)).Complement(), nil
+	case "<":
+		return NewConstraint(Interval{Upper: v}), nil
+	case "<=":
+		return NewConstraint(Interval{Upper: successor(v)}), nil
+	case ">":
+		return NewConstraint(Interval{Lower: successor(v)}), nil
+	case "^":
+		return NewConstraint(v.CompatibleRange(Caret)), nil
+	case "~":
+		return NewConstraint(v.CompatibleRange(Tilde)), nil
+	default: // ">="
+		return NewConstraint(Interval{Lower: v}), nil
+	}
+}
+
+// NewConstraint returns the constraint of versions in any of the intervals.
+func NewConstraint(intervals ...Interval) *Constraint {
+	return &Constraint{intervals: normalizeIntervals(intervals)}
+}
+
+// AnyVersion returns the constraint of all versions.
+func AnyVersion() *Constraint {
+	return NewConstraint(Interval{})
+}
+
+// NoVersion returns the constraint of no version.
+func NoVersion() *Constraint {
+	return NewConstraint()
+}
+
+// Check reports whether the version satisfies the constraint.
+func (c *Constraint) Check(v *Version) bool {
+	for _, i := range c.intervals {
+		if i.Contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Intervals returns the disjoint intervals of the constraint, from the least versions.
+func (c *Constraint) Intervals() []Interval {
+	return append([]Interval(nil), c.intervals...)
+}
+
+// IsAny reports whether the constraint holds for all versions.
+func (c *Constraint) IsAny() bool {
+	return len(c.intervals) == 1 && c.intervals[0].Lower == nil && c.intervals[0].Upper == nil
+}
+
+// IsEmpty reports whether the constraint holds for no version.
+func (c *Constraint) IsEmpty() bool {
+	return len(c.intervals) == 0
+}
+
+// Intersect returns the constraint of versions satisfying both constraints.
+func (c *Constraint) Intersect(c2 *Constraint) *Constraint {
+	intervals := make([]Interval, 0)
+	for _, i := range c.intervals {
+		for _, i2 := range c2.intervals {
+			intervals = append(intervals, i.intersect(i2))
+		}
+	}
+	return NewConstraint(intervals...)
+}
+
+// Union returns the constraint of versions satisfying either constraint.
+func (c *Constraint) Union(c2 *Constraint) *Constraint {
+	return NewConstraint(append(c.Intervals(), c2.intervals...)...)
+}
+
+// Complement returns the constraint of versions not satisfying the constraint.
+func (c *Constraint) Complement() *Constraint {
+	intervals := make([]Interval, 0, len(c.intervals)+1)
+	var lower *Version
+	for i, interval := range c.intervals {
+		if i > 0 || interval.Lower != nil {
+			intervals = append(intervals, Interval{Lower: lower, Upper: interval.Lower})
+		}
+		lower = interval.Upper
+		if lower == nil {
+			return NewConstraint(intervals...)
+		}
+	}
+	return NewConstraint(append(intervals, Interval{Lower: lower})...)
+}
+
+// Difference returns the constraint of versions satisfying the constraint but not the other one.
+func (c *Constraint) Difference(c2 *Constraint) *Constraint {
+	return c.Intersect(c2.Complement())
+}
+
+// Allows reports whether all versions satisfying the other constraint satisfy the constraint.
+func (c *Constraint) Allows(c2 *Constraint) bool {
+	return c2.Difference(c).IsEmpty()
+}
+
+// Equal reports whether two constraints hold for the same versions.
+func (c *Constraint) Equal(c2 *Constraint) bool {
+	return c.Allows(c2) && c2.Allows(c)
+}
+
+// String returns the expression the constraint is read from,
+// or the intervals separated by `||` if the constraint is not read but combined, or `none` if it is empty.
+func (c *Constraint) String() string {
+	if c.source != "" {
+		return c.source
+	}
+	if c.IsEmpty() {
+		return "none"
+	}
+	parts := make([]string, len(c.intervals))
+	for i, interval := range c.intervals {
+		parts[i] = interval.String()
+	}
+	return strings.Join(parts, " || ")
+}
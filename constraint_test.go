@@ -0,0 +1,129 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func mustParse(t *testing.T, layout string, versionString string) *version.Version {
+	v, err := version.Parse(layout, versionString)
+	if err != nil {
+		t.Fatalf("unexpected error: %v; layout: %v, version: %v", err, layout, versionString)
+	}
+	return v
+}
+
+func TestParseConstraint(t *testing.T) {
+	cases := []struct {
+		Expr       string
+		Satisfying []string
+		Rejected   []string
+	}{
+		{">=1.2,<2", []string{"1.2", "1.2.0", "1.9.9", "1.10"}, []string{"1.1.9", "2", "2.0.1"}},
+		{">1.2, <=2", []string{"1.2.1", "2", "2.0.0"}, []string{"1.2", "2.0.1"}},
+		{"1.2", []string{"1.2.0"}, []string{"1.2.1", "1.1"}},
+		{"==1.2 || =1.4", []string{"1.2", "1.4"}, []string{"1.3"}},
+		{"!=1.3", []string{"1.2", "1.3.1"}, []string{"1.3.0"}},
+		{"<1 || >=2, !=2.1 || 3.0.1", []string{"0.9", "2", "2.2", "3.0.1"}, []string{"1", "1.5", "2.1", "2.1.0"}},
+		{">=2, <1", nil, []string{"0", "1.5", "2"}},
+		{"^1.2", []string{"1.2", "1.9.9"}, []string{"1.1", "2"}},
+		{"^0.2 || ~1.4.1", []string{"0.2.5", "1.4.9"}, []string{"0.3", "1.4", "1.5"}},
+	}
+
+	for _, c := range cases {
+		constraint, err := version.ParseConstraint("5$.4$.3", c.Expr)
+		if err != nil {
+			t.Errorf("unexpected error: %v; expr: %v", err, c.Expr)
+			continue
+		}
+		if constraint.String() != c.Expr {
+			t.Errorf("string expectation failed, expected: %v, actual: %v", c.Expr, constraint)
+		}
+		for _, s := range c.Satisfying {
+			if !constraint.Check(mustParse(t, "5$.4$.3", s)) {
+				t.Errorf("%v expected to satisfy %v", s, c.Expr)
+			}
+		}
+		for _, s := range c.Rejected {
+			if constraint.Check(mustParse(t, "5$.4$.3", s)) {
+				t.Errorf("%v expected not to satisfy %v", s, c.Expr)
+			}
+		}
+	}
+}
+
+func TestParseConstraintError(t *testing.T) {
+	for _, expr := range []string{"", " ", ">=1.2,", "||1", ">=x", "~>1.2"} {
+		_, err := version.ParseConstraint("5$.4$.3", expr)
+		var constraintErr *version.ConstraintError
+		if !errors.As(err, &constraintErr) {
+			t.Errorf("error expectation failed, expected: %T, actual: %v; expr: %v", constraintErr, err, expr)
+		}
+	}
+	if _, err := version.ParseConstraint("5.4[", ">=1"); err == nil {
+		t.Errorf("ill-formed layout accepted")
+	}
+}
+
+func TestConstraintSet(t *testing.T) {
+	l := version.MustCompile("5$.4$.3")
+	parse := func(expr string) *version.Constraint {
+		c, err := l.ParseConstraint(expr)
+		if err != nil {
+			t.Fatalf("unexpected error: %v; expr: %v", err, expr)
+		}
+		return c
+	}
+
+	cases := []struct {
+		Actual   *version.Constraint
+		Expected *version.Constraint
+		String   string
+	}{
+		{parse(">=1, <3").Intersect(parse(">=2, <4")), parse(">=2, <3"), ">=2.0.0, <3.0.0"},
+		{parse(">=1, <2").Union(parse(">=2, <3")), parse(">=1, <3"), ">=1.0.0, <3.0.0"},
+		{parse(">=1, <2").Union(parse(">=3")), parse("<2 || >=3").Intersect(parse(">=1")), ">=1.0.0, <2.0.0 || >=3.0.0"},
+		{parse(">=1, <2").Complement(), parse("<1 || >=2"), "<1.0.0 || >=2.0.0"},
+		{parse(">=1").Difference(parse("1.5")), parse(">=1, !=1.5"), ">=1.0.0, <1.5.0 || >=1.5.0.1"},
+		{version.AnyVersion().Complement(), version.NoVersion(), "none"},
+		{version.NoVersion().Complement(), version.AnyVersion(), "*"},
+		{parse("<3").Union(parse("<0 || >=2")), version.AnyVersion(), "*"},
+		{version.NewConstraint(version.Point(mustParse(t, "5$.4$.3", "1.2"))), parse("1.2"), "1.2.0"},
+	}
+
+	for _, c := range cases {
+		if !c.Actual.Equal(c.Expected) {
+			t.Errorf("constraint expectation failed, expected: %v, actual: %v", c.Expected, c.Actual)
+		}
+		if c.Actual.String() != c.String {
+			t.Errorf("string expectation failed, expected: %v, actual: %v", c.String, c.Actual)
+		}
+	}
+
+	if !parse(">=1").Allows(parse(">=1.2, <2")) || parse(">=1.2, <2").Allows(parse(">=1")) {
+		t.Errorf("allowing expectation failed")
+	}
+	if !version.AnyVersion().IsAny() || !version.NoVersion().IsEmpty() || parse(">=1").IsAny() {
+		t.Errorf("any or empty expectation failed")
+	}
+}
@@ -0,0 +1,126 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DroppedField is a field of a version lost in a conversion, and its value before the conversion.
+type DroppedField struct {
+	Field Field
+	Value string
+}
+
+// LossyConversionError lists the fields lost when a version string is converted to another layout.
+type LossyConversionError struct {
+	Input   string
+	Output  string
+	Dropped []DroppedField
+}
+
+func (e *LossyConversionError) Error() string {
+	dropped := make([]string, len(e.Dropped))
+	for i, field := range e.Dropped {
+		dropped[i] = fmt.Sprintf("%v %s", field.Field, field.Value)
+	}
+	return fmt.Sprintf("lossy conversion from %s to %s, dropped: %s", e.Input, e.Output, strings.Join(dropped, ", "))
+}
+
+func (field Field) valueString(v *Version) string {
+	switch field {
+	case preRelTag:
+		return v.PreRel.String()
+	case other:
+		return v.Other
+	default:
+		return formatInt(field.value(v))
+	}
+}
+
+// droppedFields returns the fields of a version which are not the same in another version.
+func droppedFields(v *Version, v2 *Version) []DroppedField {
+	dropped := make([]DroppedField, 0)
+	for _, field := range []Field{major, minor, patch, preRelTag, build} {
+		if field.value(v) != field.value(v2) {
+			dropped = append(dropped, DroppedField{field, field.valueString(v)})
+		}
+	}
+	if v.Other != v2.Other {
+		dropped = append(dropped, DroppedField{other, v.Other})
+	}
+	return dropped
+}
+
+// checkFormat returns a LossyConversionError if the version is not read back the same from the formatted string.
+func checkFormat(l *Layout, v *Version, input string, output string) error {
+	v2, err := l.Parse(output)
+	if err != nil {
+		return fmt.Errorf("formatted version string %s not readable: %w", output, err)
+	}
+	if dropped := droppedFields(v, v2); len(dropped) > 0 {
+		return &LossyConversionError{Input: input, Output: output, Dropped: dropped}
+	}
+	return nil
+}
+
+func convert(from *Layout, to *Layout, versionString string) (string, error) {
+	v, err := from.Parse(versionString)
+	if err != nil {
+		return "", err
+	}
+	output := to.Format(v)
+	return output, checkFormat(to, v, versionString, output)
+}
+
+// Convert reads a version string in a layout, and writes it in another layout.
+//
+// If the version is not kept in the other layout, e.g. a non-zero build in `5.4.3`,
+// the converted string is returned with a LossyConversionError naming the dropped fields.
+func Convert(fromLayout string, toLayout string, versionString string) (string, error) {
+	from, err := Compile(fromLayout)
+	if err != nil {
+		return "", err
+	}
+	to, err := Compile(toLayout)
+	if err != nil {
+		return "", err
+	}
+	return convert(from, to, versionString)
+}
+
+// ConvertAll converts version strings like Convert, and returns the converted strings and errors in the same order,
+// or an error if any layout cannot be compiled.
+func ConvertAll(fromLayout string, toLayout string, versionStrings []string) ([]string, []error, error) {
+	from, err := Compile(fromLayout)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := Compile(toLayout)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputs := make([]string, len(versionStrings))
+	errs := make([]error, len(versionStrings))
+	for i, versionString := range versionStrings {
+		outputs[i], errs[i] = convert(from, to, versionString)
+	}
+	return outputs, errs, nil
+}
@@ -0,0 +1,119 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		From     string
+		To       string
+		Input    string
+		Expected string
+		Dropped  []version.DroppedField
+	}{
+		{
+			"5.4.3b1",
+			"v5.4.3-beta.1",
+			"1.2.3b4",
+			"v1.2.3-beta.4",
+			nil,
+		},
+		{
+			"5.4.3-beta.1",
+			"5.4.3",
+			"1.2.3-rc.4",
+			"1.2.3",
+			[]version.DroppedField{
+				{version.PreRelField, "rc"},
+				{version.BuildField, "4"},
+			},
+		},
+		{
+			"5.4.3.1",
+			"5.4[.3]",
+			"1.2.0.4",
+			"1.2",
+			[]version.DroppedField{
+				{version.BuildField, "4"},
+			},
+		},
+		{
+			"5.4.3+o",
+			"5.4.3",
+			"1.2.3+linux",
+			"1.2.3",
+			[]version.DroppedField{
+				{version.OtherField, "linux"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		s, err := version.Convert(c.From, c.To, c.Input)
+		if s != c.Expected {
+			t.Errorf("version expectation failed, expected: %v, actual: %v; from: %v, to: %v, input: %v", c.Expected, s, c.From, c.To, c.Input)
+		}
+		var lossErr *version.LossyConversionError
+		if c.Dropped == nil {
+			if err != nil {
+				t.Errorf("unexpected error: %v; from: %v, to: %v, input: %v", err, c.From, c.To, c.Input)
+			}
+			continue
+		}
+		if !errors.As(err, &lossErr) {
+			t.Errorf("error expectation failed, expected: %T, actual: %v", lossErr, err)
+			continue
+		}
+		if len(lossErr.Dropped) != len(c.Dropped) {
+			t.Errorf("dropped fields expectation failed, expected: %v, actual: %v", c.Dropped, lossErr.Dropped)
+			continue
+		}
+		for i := range c.Dropped {
+			if lossErr.Dropped[i] != c.Dropped[i] {
+				t.Errorf("dropped fields expectation failed, expected: %v, actual: %v", c.Dropped, lossErr.Dropped)
+			}
+		}
+	}
+}
+
+func TestConvertAll(t *testing.T) {
+	outputs, errs, err := version.ConvertAll("5.4.3b1", "v5.4.3-beta.1", []string{"1.2.3b4", "1.2.x", "1.2.3rc1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"v1.2.3-beta.4", "", "v1.2.3-rc.1"}
+	for i := range expected {
+		if outputs[i] != expected[i] {
+			t.Errorf("version expectation failed, expected: %v, actual: %v", expected[i], outputs[i])
+		}
+	}
+	if errs[0] != nil || errs[1] == nil || errs[2] != nil {
+		t.Errorf("error expectation failed, actual: %v", errs)
+	}
+
+	if _, _, err := version.ConvertAll("5.4[", "5.4", nil); err == nil {
+		t.Errorf("ill-formed layout accepted")
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// binaryFormat is the first byte of the binary encoding, incremented when the encoding changes incompatibly.
+const binaryFormat = 1
+
+var errBinaryTruncated = errors.New("binary data truncated")
+
+// MarshalBinary encodes a version as bytes, implementing encoding.BinaryMarshaler.
+//
+// The encoding is a format byte, the count of counters as a uvarint, the counters as zigzag varints,
+// i.e. major, minor, patch, pre-release tag and build, and the other text as a uvarint length and the bytes if not empty.
+// Counters added in the future are appended, and ignored by earlier decoders.
+func (v Version) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 2+len(sortKeyFields)*binary.MaxVarintLen64+len(v.Other))
+	buf := make([]byte, binary.MaxVarintLen64)
+	data = append(data, binaryFormat)
+	data = append(data, buf[:binary.PutUvarint(buf, uint64(len(sortKeyFields)))]...)
+	for _, field := range sortKeyFields {
+		data = append(data, buf[:binary.PutVarint(buf, field.value(&v))]...)
+	}
+	if v.Other != "" {
+		data = append(data, buf[:binary.PutUvarint(buf, uint64(len(v.Other)))]...)
+		data = append(data, v.Other...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a version encoded by MarshalBinary, implementing encoding.BinaryUnmarshaler.
+// Counters missing in the data are zero.
+func (v *Version) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errBinaryTruncated
+	}
+	if data[0] != binaryFormat {
+		return fmt.Errorf("binary version of unknown format %d", data[0])
+	}
+	data = data[1:]
+	count, n := binary.Uvarint(data)
+	if n <= 0 {
+		return errBinaryTruncated
+	}
+	data = data[n:]
+	decoded := Version{}
+	for i := uint64(0); i < count; i++ {
+		val, n := binary.Varint(data)
+		if n <= 0 {
+			return errBinaryTruncated
+		}
+		data = data[n:]
+		if i < uint64(len(sortKeyFields)) {
+			sortKeyFields[i].SetField(&decoded, val)
+		}
+	}
+	if len(data) > 0 {
+		length, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < length {
+			return errBinaryTruncated
+		}
+		if uint64(len(data)-n) > length {
+			return fmt.Errorf("binary version with %d trailing bytes", uint64(len(data)-n)-length)
+		}
+		decoded.Other = string(data[n:])
+	}
+	*v = decoded
+	return nil
+}
+
+// GobEncode encodes a version for encoding/gob in the binary encoding,
+// so that the encoding does not change with the fields of Version.
+func (v Version) GobEncode() ([]byte, error) {
+	return v.MarshalBinary()
+}
+
+// GobDecode decodes a version encoded by GobEncode.
+func (v *Version) GobDecode(data []byte) error {
+	return v.UnmarshalBinary(data)
+}
+
+// MarshalBinary encodes a layout as bytes, implementing encoding.BinaryMarshaler.
+// The encoding is the format byte followed by the source of the layout.
+func (l *Layout) MarshalBinary() ([]byte, error) {
+	return append([]byte{binaryFormat}, l.source...), nil
+}
+
+// UnmarshalBinary decodes a layout encoded by MarshalBinary and compiles it, implementing encoding.BinaryUnmarshaler.
+func (l *Layout) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errBinaryTruncated
+	}
+	if data[0] != binaryFormat {
+		return fmt.Errorf("binary layout of unknown format %d", data[0])
+	}
+	compiled, err := Compile(string(data[1:]))
+	if err != nil {
+		return err
+	}
+	*l = *compiled
+	return nil
+}
+
+// GobEncode encodes a layout for encoding/gob in the binary encoding.
+func (l *Layout) GobEncode() ([]byte, error) {
+	return l.MarshalBinary()
+}
+
+// GobDecode decodes a layout encoded by GobEncode.
+func (l *Layout) GobDecode(data []byte) error {
+	return l.UnmarshalBinary(data)
+}
@@ -0,0 +1,145 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	cases := []struct {
+		Version  *version.Version
+		Expected []byte
+	}{
+		{&version.Version{}, []byte{1, 5, 0, 0, 0, 0, 0}},
+		{&version.Version{Major: 1, Minor: 2, Patch: 3, PreRel: version.Beta, Build: 64}, []byte{1, 5, 2, 4, 6, 3, 0x80, 0x01}},
+		{&version.Version{Major: 1, Other: "+linux"}, []byte{1, 5, 2, 0, 0, 0, 0, 6, '+', 'l', 'i', 'n', 'u', 'x'}},
+	}
+
+	for _, c := range cases {
+		data, err := c.Version.MarshalBinary()
+		if err != nil {
+			t.Errorf("unexpected error: %v; version: %+v", err, c.Version)
+			continue
+		}
+		if !bytes.Equal(data, c.Expected) {
+			t.Errorf("binary expectation failed, expected: %v, actual: %v; version: %+v", c.Expected, data, c.Version)
+		}
+		v := &version.Version{Major: 9, Other: "stale"}
+		if err := v.UnmarshalBinary(data); err != nil {
+			t.Errorf("unexpected error: %v; data: %v", err, data)
+			continue
+		}
+		if *v != *c.Version {
+			t.Errorf("version expectation failed, expected: %+v, actual: %+v", c.Version, v)
+		}
+	}
+}
+
+func TestUnmarshalBinary(t *testing.T) {
+	// fewer counters, as if encoded before a counter is added
+	v := &version.Version{}
+	if err := v.UnmarshalBinary([]byte{1, 2, 2, 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *v != (version.Version{Major: 1, Minor: 2}) {
+		t.Errorf("version expectation failed, actual: %+v", v)
+	}
+
+	// more counters, as if encoded after a counter is added
+	if err := v.UnmarshalBinary([]byte{1, 6, 2, 4, 6, 0, 8, 10, 1, 'x'}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *v != (version.Version{Major: 1, Minor: 2, Patch: 3, Build: 4, Other: "x"}) {
+		t.Errorf("version expectation failed, actual: %+v", v)
+	}
+
+	for _, data := range [][]byte{
+		{},
+		{2, 5, 0, 0, 0, 0, 0},
+		{1},
+		{1, 5, 0, 0},
+		{1, 5, 0, 0, 0, 0, 0, 3, 'a'},
+		{1, 5, 0, 0, 0, 0, 0, 1, 'a', 'b'},
+	} {
+		if err := v.UnmarshalBinary(data); err == nil {
+			t.Errorf("ill-formed data accepted: %v", data)
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+	type release struct {
+		Name    string
+		Version *version.Version
+		Deps    []version.Version
+	}
+	r := release{
+		Name:    "app",
+		Version: &version.Version{Major: 1, Minor: 2, PreRel: version.ReleaseCandidate, Build: 3, Other: "+build.5"},
+		Deps:    []version.Version{{Major: 2}, {Minor: 1, Patch: 7}},
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var r2 release
+	if err := gob.NewDecoder(&buf).Decode(&r2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r2.Name != r.Name || *r2.Version != *r.Version || len(r2.Deps) != 2 || r2.Deps[0] != r.Deps[0] || r2.Deps[1] != r.Deps[1] {
+		t.Errorf("gob expectation failed, expected: %+v, actual: %+v", r, r2)
+	}
+}
+
+func TestGobLayout(t *testing.T) {
+	type scheme struct {
+		Layout *version.Layout
+		Latest version.Version
+	}
+	s := scheme{Layout: version.MustCompile("[v]5.4$.3-beta.1"), Latest: version.Version{Major: 1, Minor: 2}}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var s2 scheme
+	if err := gob.NewDecoder(&buf).Decode(&s2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s2.Layout.String() != s.Layout.String() || s2.Latest != s.Latest {
+		t.Errorf("gob expectation failed, expected: %+v, actual: %+v", s, s2)
+	}
+	if actual := s2.Layout.Format(&s2.Latest); actual != "1.2" {
+		t.Errorf("version expectation failed, expected: 1.2, actual: %v", actual)
+	}
+
+	l := &version.Layout{}
+	if err := l.UnmarshalBinary([]byte{1, '5', '['}); err == nil {
+		t.Errorf("ill-formed layout accepted")
+	}
+	if err := l.UnmarshalBinary(nil); err == nil {
+		t.Errorf("empty data accepted")
+	}
+}
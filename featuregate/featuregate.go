@@ -0,0 +1,158 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package featuregate enables features by the version of a client,
+// where features are declared with the versions they are introduced and removed.
+package featuregate
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gsxab/go-version"
+)
+
+// Option sets a bound of a feature declaration.
+type Option func(d *declaration)
+
+type declaration struct {
+	since string
+	until string
+}
+
+// Since sets the version a feature is introduced, read in the layout of the registry.
+func Since(versionString string) Option {
+	return func(d *declaration) {
+		d.since = versionString
+	}
+}
+
+// Until sets the version a feature is removed, read in the layout of the registry.
+// The feature is not enabled for the version itself.
+func Until(versionString string) Option {
+	return func(d *declaration) {
+		d.until = versionString
+	}
+}
+
+// OverlapError tells a feature is declared for versions it is already declared for.
+type OverlapError struct {
+	Feature  string
+	Existing version.Interval
+	Declared version.Interval
+}
+
+func (e *OverlapError) Error() string {
+	return fmt.Sprintf("feature %s declared for %v, overlapping %v", e.Feature, e.Declared, e.Existing)
+}
+
+// Registry is a set of features declared with versions, safe for concurrent use.
+type Registry struct {
+	layout   *version.Layout
+	mu       sync.RWMutex
+	features map[string][]version.Interval
+}
+
+// New returns an empty registry, where versions are read in the layout.
+func New(layout *version.Layout) *Registry {
+	return &Registry{layout: layout, features: make(map[string][]version.Interval)}
+}
+
+// Declare declares a feature enabled from the version Since to the version Until,
+// either of which is unbounded if not set.
+// A feature may be declared more than once, e.g. reintroduced after removed,
+// and an OverlapError is returned if the versions overlap a declaration before.
+func (r *Registry) Declare(feature string, options ...Option) error {
+	d := &declaration{}
+	for _, option := range options {
+		option(d)
+	}
+	interval := version.Interval{}
+	var err error
+	if d.since != "" {
+		if interval.Lower, err = r.layout.Parse(d.since); err != nil {
+			return fmt.Errorf("feature %s since %s: %w", feature, d.since, err)
+		}
+	}
+	if d.until != "" {
+		if interval.Upper, err = r.layout.Parse(d.until); err != nil {
+			return fmt.Errorf("feature %s until %s: %w", feature, d.until, err)
+		}
+	}
+	if interval.IsEmpty() {
+		return fmt.Errorf("feature %s declared for no version, since %s until %s", feature, d.since, d.until)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	declared := version.NewConstraint(interval)
+	for _, existing := range r.features[feature] {
+		if !version.NewConstraint(existing).Intersect(declared).IsEmpty() {
+			return &OverlapError{Feature: feature, Existing: existing, Declared: interval}
+		}
+	}
+	r.features[feature] = append(r.features[feature], interval)
+	return nil
+}
+
+// MustDeclare declares a feature like Declare, but panics if it fails, to declare features on initialization.
+func (r *Registry) MustDeclare(feature string, options ...Option) {
+	if err := r.Declare(feature, options...); err != nil {
+		panic(err)
+	}
+}
+
+// Enabled reports whether a feature is enabled for the version.
+// A feature not declared is not enabled.
+func (r *Registry) Enabled(feature string, v *version.Version) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return enabled(r.features[feature], v)
+}
+
+// EnabledFeatures returns the features enabled for the version, in order.
+func (r *Registry) EnabledFeatures(v *version.Version) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	features := make([]string, 0)
+	for feature, intervals := range r.features {
+		if enabled(intervals, v) {
+			features = append(features, feature)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// Versions returns the versions a feature is enabled for, or false if it is not declared.
+func (r *Registry) Versions(feature string) (*version.Constraint, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	intervals, ok := r.features[feature]
+	return version.NewConstraint(intervals...), ok
+}
+
+func enabled(intervals []version.Interval, v *version.Version) bool {
+	for _, interval := range intervals {
+		if interval.Contains(v) {
+			return true
+		}
+	}
+	return false
+}
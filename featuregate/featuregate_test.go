@@ -0,0 +1,135 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package featuregate_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gsxab/go-version"
+	"github.com/gsxab/go-version/featuregate"
+)
+
+var layout = version.MustCompile("5.4$.3")
+
+func mustParse(t *testing.T, versionString string) *version.Version {
+	v, err := layout.Parse(versionString)
+	if err != nil {
+		t.Fatalf("unexpected error: %v; version: %v", err, versionString)
+	}
+	return v
+}
+
+func TestRegistry(t *testing.T) {
+	gates := featuregate.New(layout)
+	gates.MustDeclare("streaming", featuregate.Since("1.4"))
+	gates.MustDeclare("legacy-auth", featuregate.Until("2.0"))
+	gates.MustDeclare("batch", featuregate.Since("1.2"), featuregate.Until("1.5"))
+	gates.MustDeclare("batch", featuregate.Since("2.1"))
+
+	cases := []struct {
+		Version  string
+		Expected []string
+	}{
+		{"1.0", []string{"legacy-auth"}},
+		{"1.2", []string{"batch", "legacy-auth"}},
+		{"1.4.1", []string{"batch", "legacy-auth", "streaming"}},
+		{"1.5", []string{"legacy-auth", "streaming"}},
+		{"2.0", []string{"streaming"}},
+		{"2.1", []string{"batch", "streaming"}},
+	}
+
+	for _, c := range cases {
+		v := mustParse(t, c.Version)
+		if actual := gates.EnabledFeatures(v); strings.Join(actual, " ") != strings.Join(c.Expected, " ") {
+			t.Errorf("features expectation failed, expected: %v, actual: %v; version: %v", c.Expected, actual, c.Version)
+		}
+		for _, feature := range []string{"streaming", "legacy-auth", "batch"} {
+			expected := false
+			for _, e := range c.Expected {
+				expected = expected || e == feature
+			}
+			if actual := gates.Enabled(feature, v); actual != expected {
+				t.Errorf("enabled expectation failed, expected: %v, actual: %v; feature: %v, version: %v", expected, actual, feature, c.Version)
+			}
+		}
+	}
+
+	if gates.Enabled("unknown", mustParse(t, "1.0")) {
+		t.Errorf("undeclared feature enabled")
+	}
+	if versions, ok := gates.Versions("batch"); !ok || versions.String() != ">=1.2.0, <1.5.0 || >=2.1.0" {
+		t.Errorf("versions expectation failed, actual: %v, %v", versions, ok)
+	}
+	if _, ok := gates.Versions("unknown"); ok {
+		t.Errorf("undeclared feature found")
+	}
+}
+
+func TestDeclareError(t *testing.T) {
+	gates := featuregate.New(layout)
+	gates.MustDeclare("streaming", featuregate.Since("1.4"), featuregate.Until("2.0"))
+
+	var overlapErr *featuregate.OverlapError
+	if err := gates.Declare("streaming", featuregate.Since("1.9")); !errors.As(err, &overlapErr) || overlapErr.Feature != "streaming" {
+		t.Errorf("error expectation failed, expected: %T, actual: %v", overlapErr, err)
+	}
+	if err := gates.Declare("streaming", featuregate.Until("1.4")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, options := range [][]featuregate.Option{
+		{featuregate.Since("2.0"), featuregate.Until("1.0")},
+		{featuregate.Since("1.x")},
+		{featuregate.Until("2.x")},
+	} {
+		if err := gates.Declare("other", options...); err == nil {
+			t.Errorf("ill-formed declaration accepted")
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("overlapping declaration accepted")
+		}
+	}()
+	gates.MustDeclare("streaming")
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	gates := featuregate.New(layout)
+	v := mustParse(t, "1.5")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			gates.MustDeclare(string(rune('a'+i)), featuregate.Since("1.0"))
+			for j := 0; j < 100; j++ {
+				gates.Enabled("a", v)
+				gates.EnabledFeatures(v)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if features := gates.EnabledFeatures(v); len(features) != 8 {
+		t.Errorf("features expectation failed, actual: %v", features)
+	}
+}
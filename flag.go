@@ -0,0 +1,131 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"flag"
+	"fmt"
+)
+
+// FlagValue is a flag.Getter reading a version in a layout.
+type FlagValue struct {
+	Layout  *Layout
+	Version *Version
+}
+
+// String writes the version in the layout, or returns an empty string if either is nil.
+func (f *FlagValue) String() string {
+	if f == nil || f.Layout == nil || f.Version == nil {
+		return ""
+	}
+	return f.Layout.Format(f.Version)
+}
+
+// Set reads the version in the layout.
+func (f *FlagValue) Set(s string) error {
+	v, err := f.Layout.Parse(s)
+	if err != nil {
+		return err
+	}
+	*f.Version = *v
+	return nil
+}
+
+// Get returns the version as a *Version.
+func (f *FlagValue) Get() interface{} {
+	return f.Version
+}
+
+// ConstraintFlagValue is a flag.Getter reading a constraint expression, where versions are in a layout.
+type ConstraintFlagValue struct {
+	Layout     *Layout
+	Constraint *Constraint
+}
+
+// String returns the expression of the constraint, or an empty string if it is nil.
+func (f *ConstraintFlagValue) String() string {
+	if f == nil || f.Constraint == nil {
+		return ""
+	}
+	return f.Constraint.source
+}
+
+// Set reads the constraint expression.
+func (f *ConstraintFlagValue) Set(s string) error {
+	c, err := f.Layout.ParseConstraint(s)
+	if err != nil {
+		return err
+	}
+	*f.Constraint = *c
+	return nil
+}
+
+// Get returns the constraint as a *Constraint.
+func (f *ConstraintFlagValue) Get() interface{} {
+	return f.Constraint
+}
+
+// Flag defines a version flag in the flag set, or in flag.CommandLine if it is nil,
+// and returns the address of the version, which is the default value until the flag is set.
+// An empty default value is the zero version.
+// It panics if the layout cannot be compiled or the default value cannot be read, like MustCompile.
+func Flag(fs *flag.FlagSet, name string, layout string, value string, usage string) *Version {
+	v := &Version{}
+	FlagVar(fs, v, name, layout, value, usage)
+	return v
+}
+
+// FlagVar defines a version flag like Flag, storing the version in the given address.
+func FlagVar(fs *flag.FlagSet, v *Version, name string, layout string, value string, usage string) {
+	f := &FlagValue{Layout: MustCompile(layout), Version: v}
+	if value == "" {
+		*v = Version{}
+	} else if err := f.Set(value); err != nil {
+		panic(fmt.Sprintf("default value of flag %s: %v", name, err))
+	}
+	flagSet(fs).Var(f, name, usage)
+}
+
+// ConstraintFlag defines a constraint flag in the flag set, or in flag.CommandLine if it is nil,
+// and returns the address of the constraint, which is the default value until the flag is set.
+// An empty default value holds for all versions.
+// It panics if the layout cannot be compiled or the default value cannot be read, like MustCompile.
+func ConstraintFlag(fs *flag.FlagSet, name string, layout string, value string, usage string) *Constraint {
+	c := &Constraint{}
+	ConstraintFlagVar(fs, c, name, layout, value, usage)
+	return c
+}
+
+// ConstraintFlagVar defines a constraint flag like ConstraintFlag, storing the constraint in the given address.
+func ConstraintFlagVar(fs *flag.FlagSet, c *Constraint, name string, layout string, value string, usage string) {
+	f := &ConstraintFlagValue{Layout: MustCompile(layout), Constraint: c}
+	if value == "" {
+		*c = *AnyVersion()
+	} else if err := f.Set(value); err != nil {
+		panic(fmt.Sprintf("default value of flag %s: %v", name, err))
+	}
+	flagSet(fs).Var(f, name, usage)
+}
+
+func flagSet(fs *flag.FlagSet) *flag.FlagSet {
+	if fs == nil {
+		return flag.CommandLine
+	}
+	return fs
+}
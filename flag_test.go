@@ -0,0 +1,77 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	minVersion := version.Flag(fs, "min-version", "[v]5.4$.3", "1.0", "minimum version")
+	maxVersion := version.Flag(fs, "max-version", "[v]5.4$.3", "", "maximum version")
+	constraint := version.ConstraintFlag(fs, "accept", "5$.4$.3", ">=1.2, <2", "accepted versions")
+	all := version.ConstraintFlag(fs, "any", "5$.4$.3", "", "accepted versions")
+
+	if *minVersion != (version.Version{Major: 1}) || *maxVersion != (version.Version{}) {
+		t.Errorf("default expectation failed, actual: %+v, %+v", minVersion, maxVersion)
+	}
+	if constraint.String() != ">=1.2, <2" || !all.IsAny() {
+		t.Errorf("default expectation failed, actual: %v, %v", constraint, all)
+	}
+
+	if err := fs.Parse([]string{"--min-version=v1.2", "--accept", ">=2 || 1.5"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *minVersion != (version.Version{Major: 1, Minor: 2}) {
+		t.Errorf("version expectation failed, actual: %+v", minVersion)
+	}
+	if !constraint.Check(&version.Version{Major: 1, Minor: 5}) || constraint.Check(&version.Version{Major: 1, Minor: 2}) {
+		t.Errorf("constraint expectation failed, actual: %v", constraint)
+	}
+
+	getter := fs.Lookup("min-version").Value.(flag.Getter)
+	if getter.String() != "1.2" || getter.Get().(*version.Version) != minVersion {
+		t.Errorf("getter expectation failed, actual: %v, %v", getter.String(), getter.Get())
+	}
+	getter = fs.Lookup("accept").Value.(flag.Getter)
+	if getter.String() != ">=2 || 1.5" || getter.Get().(*version.Constraint) != constraint {
+		t.Errorf("getter expectation failed, actual: %v, %v", getter.String(), getter.Get())
+	}
+
+	for _, args := range [][]string{{"--min-version=1.x"}, {"--accept=>=1.2,"}} {
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("ill-formed flag accepted: %v", args)
+		}
+	}
+}
+
+func TestFlagPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("ill-formed default value accepted")
+		}
+	}()
+	version.Flag(flag.NewFlagSet("test", flag.ContinueOnError), "v", "5.4.3", "1.x", "")
+}
@@ -18,6 +18,12 @@
 
 package version
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 type Field int
 
 const (
@@ -33,17 +39,113 @@ const (
 	allowEnd
 	alphabetic_build
 	alphabetic_patch
+	required
+	group
+	choice
+	// calendar fields, stored in major, minor or patch
+	year
+	short_year
+	month
+	week
+	day
+)
+
+// Fields of a version.
+const (
+	MajorField  = major
+	MinorField  = minor
+	PatchField  = patch
+	PreRelField = preRelTag
+	BuildField  = build
+	OtherField  = other
 )
 
+// String returns the name of the field of a version the field is stored in, or the kind of the field if not stored.
+func (field Field) String() string {
+	switch slotOf(field) {
+	case build:
+		return "build"
+	case preRelTag:
+		return "pre-release tag"
+	case patch:
+		return "patch"
+	case minor:
+		return "minor"
+	case major:
+		return "major"
+	case other:
+		return "other"
+	case fixed, required:
+		return "literal"
+	case allowEnd:
+		return "end"
+	case group:
+		return "group"
+	case choice:
+		return "choice"
+	default:
+		return fmt.Sprintf("Field(%d)", int(field))
+	}
+}
+
+var calendarChunks = []struct {
+	token string
+	field Field
+}{
+	{"YYYY", year},
+	{"YY", short_year},
+	{"0Y", short_year},
+	{"MM", month},
+	{"0M", month},
+	{"WW", week},
+	{"0W", week},
+	{"DD", day},
+	{"0D", day},
+}
+
 // format tokenizer
 
 func nextChunk(layout string) (string, Field, string, error) {
-	// number field
+	// quoted literal, returned unquoted
+	if layout[0] == '\'' {
+		text, offset, err := readQuoted(layout)
+		if err != nil {
+			return "", 0, "", err
+		}
+		return text, fixed, layout[offset:], nil
+	}
+	// required literal
+	if layout[0] == '!' {
+		if len(layout) == 1 {
+			return "", 0, "", errors.New("required mark at the end of layout")
+		}
+		if isStructural(layout[1]) {
+			return "", 0, "", fmt.Errorf("required mark before a non-literal: %s", layout[:2])
+		}
+		text, field, suffix, err := nextChunk(layout[1:])
+		if err != nil {
+			return "", 0, "", err
+		}
+		if field != fixed {
+			return "", 0, "", fmt.Errorf("required mark before a non-literal: %s", layout[:len(layout)-len(suffix)])
+		}
+		return text, required, suffix, nil
+	}
+	// calendar field, before number and alphabetic fields sharing the leading characters
+	for _, chunk := range calendarChunks {
+		if strings.HasPrefix(layout, chunk.token) {
+			return chunk.token, chunk.field, layout[len(chunk.token):], nil
+		}
+	}
+	// number field, zero-padded to the width of the chunk if it has leading zeros, e.g. `003`
 	if isAsciiNum(layout[0]) {
 		value, offset, err := readInt(layout)
 		if err != nil {
 			return "", 0, "", err
 		}
+		if value < int64(build) || value > int64(major) {
+			return "", 0, "", fmt.Errorf("unknown number field in layout: %s", layout[:offset])
+		}
 		return layout[:offset], Field(value), layout[offset:], nil
 	}
 	// alphabetic field
@@ -65,9 +167,12 @@ func nextChunk(layout string) (string, Field, string, error) {
 	if layout[0] == 'v' || layout[0] == 'V' {
 		return layout[:1], fixed, layout[1:], nil
 	}
-	// other
+	// other, also spelt out as `other`
+	if strings.HasPrefix(layout, "other") {
+		return layout[:5], other, layout[5:], nil
+	}
 	if layout[0] == 'o' {
-		return layout, other, "", nil
+		return layout[:1], other, layout[1:], nil
 	}
 	// pre-release tag
 	index := 0
@@ -88,3 +193,25 @@ func nextChunk(layout string) (string, Field, string, error) {
 	}
 	return layout[:index], preRelTag, layout[index:], nil
 }
+
+// readQuoted reads a literal quoted in single quotes, where two single quotes stand for one.
+// A pair of quotes with nothing between stands for a single quote as well.
+func readQuoted(layout string) (string, int, error) {
+	var text strings.Builder
+	for i := 1; i < len(layout); i++ {
+		if layout[i] != '\'' {
+			text.WriteByte(layout[i])
+			continue
+		}
+		if i+1 < len(layout) && layout[i+1] == '\'' {
+			text.WriteByte('\'')
+			i++
+			continue
+		}
+		if i == 1 {
+			return "'", 2, nil
+		}
+		return text.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated quote in layout: %s", layout)
+}
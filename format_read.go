@@ -20,6 +20,7 @@ package version
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -32,11 +33,11 @@ func (field Field) SetField(v *Version, val int64) {
 		v.Build = val
 	case preRelTag:
 		v.PreRel = PreRelTag(val)
-	case patch, alphabetic_patch:
+	case patch, alphabetic_patch, day:
 		v.Patch = val
-	case minor:
+	case minor, month, week:
 		v.Minor = val
-	case major:
+	case major, year, short_year:
 		v.Major = val
 	default:
 		panic("unexpected field to set")
@@ -44,12 +45,23 @@ func (field Field) SetField(v *Version, val int64) {
 }
 
 func (field Field) Read(v *Version, layout string, source string) (int, error) {
+	return field.read(v, layout, source, false)
+}
+
+// read reads a field like Read, and rejects numbers not written as Format does if strict is set,
+// i.e. with leading zeros or of another width.
+func (field Field) read(v *Version, layout string, source string, strict bool) (int, error) {
 	switch field {
 	case build, patch, minor, major:
 		val, offset, err := readInt(source)
 		if err != nil {
 			return 0, err
 		}
+		if strict {
+			if err := checkWidth(source[:offset], formatZeroPadded(val, len(layout))); err != nil {
+				return 0, err
+			}
+		}
 		field.SetField(v, val)
 		return offset, nil
 	case alphabetic_build, alphabetic_patch:
@@ -59,6 +71,13 @@ func (field Field) Read(v *Version, layout string, source string) (int, error) {
 		}
 		field.SetField(v, val)
 		return offset, nil
+	case year, short_year, month, week, day:
+		val, offset, err := readCalendar(field, layout, source, strict)
+		if err != nil {
+			return 0, err
+		}
+		field.SetField(v, val)
+		return offset, nil
 	case preRelTag:
 		tag, offset, err := readTag(layout, source)
 		if err != nil {
@@ -72,9 +91,14 @@ func (field Field) Read(v *Version, layout string, source string) (int, error) {
 		} else {
 			return 0, nil
 		}
+	case required:
+		if !strings.HasPrefix(source, layout) {
+			return 0, fmt.Errorf("literal not found: %s", layout)
+		}
+		return len(layout), nil
 	case other:
 		v.Other = source
-		return len(layout), nil
+		return len(source), nil
 	case allowEnd:
 		if source == "" {
 			return 1, nil
@@ -99,6 +123,45 @@ func readInt(source string) (int64, int, error) {
 	return val, i, nil
 }
 
+func checkWidth(actual string, expected string) error {
+	if actual != expected {
+		return fmt.Errorf("ill-formed number: %s, expected: %s", actual, expected)
+	}
+	return nil
+}
+
+func readCalendar(field Field, layout string, source string, strict bool) (int64, int, error) {
+	val, offset, err := readInt(source)
+	if err != nil {
+		return 0, 0, err
+	}
+	if strict {
+		if err := checkWidth(source[:offset], formatCalendar(layout, val)); err != nil {
+			return 0, 0, err
+		}
+	}
+	switch field {
+	case short_year:
+		if val > math.MaxInt64-2000 {
+			return 0, 0, fmt.Errorf("year out of range: %d", val)
+		}
+		val += 2000
+	case month:
+		if val < 1 || val > 12 {
+			return 0, 0, fmt.Errorf("month out of range: %d", val)
+		}
+	case week:
+		if val < 1 || val > 53 {
+			return 0, 0, fmt.Errorf("week out of range: %d", val)
+		}
+	case day:
+		if val < 1 || val > 31 {
+			return 0, 0, fmt.Errorf("day out of range: %d", val)
+		}
+	}
+	return val, offset, nil
+}
+
 func readAlpha(source string) (int64, int, error) {
 	var i int
 	for i = 0; i < len(source); i++ {
@@ -185,34 +248,186 @@ func readTag(layout string, source string) (tag PreRelTag, offset int, err error
 	return
 }
 
+// Parse reads a version string in the layout.
 func Parse(layout string, versionString string) (*Version, error) {
-	// layout example: 5.4.3-beta.1(.other)
-	v := &Version{
-		Major:  0,
-		Minor:  0,
-		Patch:  0,
-		PreRel: 0,
-		Build:  0,
-	}
-	for len(layout) > 0 {
-		fieldFmt, field, suffix, err := nextChunk(layout)
-		if err != nil {
-			return nil, err
+	l, err := Compile(layout)
+	if err != nil {
+		return nil, err
+	}
+	return l.Parse(versionString)
+}
+
+// ParseStrict reads a version string in the layout like Parse,
+// but rejects numbers not of the exact width Format writes, e.g. `1.02` in `5.4` or `1.2.3` in `05.04.003`.
+func ParseStrict(layout string, versionString string) (*Version, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return nil, err
+	}
+	return l.ParseStrict(versionString)
+}
+
+// Parse reads a version string in the layout.
+//
+// Groups are tried before being skipped, and alternatives are tried in order,
+// so the first match in this order is returned if several ones are possible.
+func (l *Layout) Parse(versionString string) (*Version, error) {
+	return l.parse(versionString, false)
+}
+
+// ParseStrict reads a version string in the layout like Parse, but rejects numbers of widths other than Format writes.
+func (l *Layout) ParseStrict(versionString string) (*Version, error) {
+	return l.parse(versionString, true)
+}
+
+func (l *Layout) parse(versionString string, strict bool) (*Version, error) {
+	result, _, err := l.match(versionString, strict, false)
+	return result, err
+}
+
+// match reads a version string, and returns the chunks read by each node if traced.
+func (l *Layout) match(versionString string, strict bool, traced bool) (*Version, *span, error) {
+	st, err := l.run(&matcher{strict: strict, traced: traced}, versionString)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &st.v, st.trace, nil
+}
+
+// run matches a version string with the matcher, and returns the state at the end.
+func (l *Layout) run(m *matcher, versionString string) (*matchState, error) {
+	// layout example: 5.4.3[-beta[.1]][+other]
+	var result matchState
+	m.done = func(source string, st matchState) error {
+		if len(source) > 0 {
+			return &matchError{len(source), fmt.Errorf("version string not ended, left: %s", source)}
+		}
+		if err := validateCalendar(&st.v, st.calendar); err != nil {
+			return &matchError{0, err}
+		}
+		result = st
+		return nil
+	}
+	if err := m.match(l.nodes, versionString, matchState{}, m.done); err != nil {
+		return nil, err.(*matchError).err
+	}
+	return &result, nil
+}
+
+// matchState is the state of a matcher on a path of backtracking.
+type matchState struct {
+	v        Version
+	calendar fieldSet // calendar fields read
+	trace    *span    // the last chunk read, if traced
+	read     fieldSet // counter fields read, including wildcards
+	wildcard fieldSet // counter fields read as wildcards, if reading a pattern
+	spelling spelling // wildcards read for the counter fields
+	early    bool     // whether an allowed end is taken
+}
+
+// span is a chunk of a version string read by a node, linked to the previous one.
+type span struct {
+	node *node
+	text string
+	prev *span
+}
+
+// continuation matches the rest of a layout.
+type continuation func(source string, st matchState) error
+
+// matcher matches a layout by backtracking.
+type matcher struct {
+	strict  bool
+	traced  bool
+	pattern bool // whether wildcards are read, like `x` in `1.2.x`
+	done    continuation
+}
+
+// matchError is an error met when matching, and the length of the source left then.
+type matchError struct {
+	left int
+	err  error
+}
+
+func (e *matchError) Error() string {
+	return e.err.Error()
+}
+
+// furthest returns the error met after reading more.
+func furthest(err1 error, err2 error) error {
+	if err2.(*matchError).left < err1.(*matchError).left {
+		return err2
+	}
+	return err1
+}
+
+func (m *matcher) match(nodes []node, source string, st matchState, k continuation) error {
+	if len(nodes) == 0 {
+		return k(source, st)
+	}
+	n := &nodes[0]
+	rest := func(source string, st matchState) error {
+		return m.match(nodes[1:], source, st, k)
+	}
+	switch n.field {
+	case allowEnd:
+		if len(source) == 0 {
+			st.early = true
+			return m.done(source, st) // allow end, and meets end of versionString
+		}
+		return rest(source, st)
+	case group:
+		err := m.matchAlternatives(n.alts, source, st, rest)
+		if err == nil {
+			return nil
 		}
-		// fmt.Printf("fieldFmt=%+v, field=%+v\n", fieldFmt, field)
-		advance, err := field.Read(v, fieldFmt, versionString)
+		if err2 := rest(source, st); err2 != nil {
+			return furthest(err, err2)
+		}
+		return nil
+	case choice:
+		return m.matchAlternatives(n.alts, source, st, rest)
+	default:
+		if m.pattern {
+			if advance, c := readWildcard(n.field, n.text, source); c != 0 {
+				st.read = st.read.with(slotOf(n.field))
+				st.wildcard = st.wildcard.with(slotOf(n.field))
+				st.spelling[slotOf(n.field)] = c
+				if m.traced {
+					st.trace = &span{node: n, text: source[:advance], prev: st.trace}
+				}
+				return rest(source[advance:], st)
+			}
+		}
+		advance, err := n.field.read(&st.v, n.text, source, m.strict)
 		if err != nil {
-			return nil, err
+			return &matchError{len(source), err}
+		}
+		if isCalendar(n.field) {
+			st.calendar = st.calendar.with(n.field)
+		}
+		if advance > 0 {
+			st.read = st.read.with(slotOf(n.field))
 		}
-		if field == allowEnd && advance == 1 {
-			break // allow end, and meets end of versionString
+		if m.traced {
+			st.trace = &span{node: n, text: source[:advance], prev: st.trace}
 		}
-		layout = suffix
-		// fmt.Printf("advance=%+v, taken=%+v, remaining=%+v\n", advance, versionString[:advance], versionString[advance:])
-		versionString = versionString[advance:]
+		return rest(source[advance:], st)
 	}
-	if len(versionString) > 0 {
-		return nil, fmt.Errorf("version string not ended, left: %s", versionString)
+}
+
+func (m *matcher) matchAlternatives(alts [][]node, source string, st matchState, k continuation) error {
+	var err error
+	for _, alt := range alts {
+		err2 := m.match(alt, source, st, k)
+		if err2 == nil {
+			return nil
+		}
+		if err == nil {
+			err = err2
+		} else {
+			err = furthest(err, err2)
+		}
 	}
-	return v, nil
+	return err
 }
@@ -44,6 +44,18 @@ func test(t *testing.T, format string, cases []tc) {
 	}
 }
 
+func testStrict(t *testing.T, format string, cases []tc) {
+	for _, c := range cases {
+		v, err := version.ParseStrict(format, c.VersionString)
+		if c.RaiseErr != (err != nil) {
+			t.Errorf("error expectation failed, expected: %v, actual: %+v; format: %+v, input: %+v", c.RaiseErr, err, format, c.VersionString)
+		}
+		if !c.RaiseErr && err == nil && !v.EQ(c.Expected) {
+			t.Errorf("version expectation failed, expected: %+v, actual: %+v; format: %+v, input: %+v", c.Expected, v, format, c.VersionString)
+		}
+	}
+}
+
 func TestMajorMinor(t *testing.T) {
 	format := "5.4"
 
@@ -786,3 +798,547 @@ func TestAlphabeticBuild(t *testing.T) {
 
 	test(t, format, cases)
 }
+
+func TestCalendar(t *testing.T) {
+	format := "YYYY.0M.0D$.1"
+
+	cases := []tc{
+		{
+			"2023.12.30",
+			&version.Version{
+				Major: 2023,
+				Minor: 12,
+				Patch: 30,
+			},
+			false,
+		},
+		{
+			"2023.12.30.1",
+			&version.Version{
+				Major: 2023,
+				Minor: 12,
+				Patch: 30,
+				Build: 1,
+			},
+			false,
+		},
+		{
+			"2024.02.29",
+			&version.Version{
+				Major: 2024,
+				Minor: 2,
+				Patch: 29,
+			},
+			false,
+		},
+		{
+			"2023.02.29",
+			nil,
+			true,
+		},
+		{
+			"2023.13.01",
+			nil,
+			true,
+		},
+		{
+			"2023.00.01",
+			nil,
+			true,
+		},
+		{
+			"2023.12",
+			nil,
+			true,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestShortYearMonth(t *testing.T) {
+	format := "YY.0M"
+
+	cases := []tc{
+		{
+			"23.04",
+			&version.Version{
+				Major: 2023,
+				Minor: 4,
+			},
+			false,
+		},
+		{
+			"6.4",
+			&version.Version{
+				Major: 2006,
+				Minor: 4,
+			},
+			false,
+		},
+		{
+			"106.11",
+			&version.Version{
+				Major: 2106,
+				Minor: 11,
+			},
+			false,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestYearWeek(t *testing.T) {
+	format := "YYYY.W0W.1"
+
+	cases := []tc{
+		{
+			"2024.W07.1",
+			&version.Version{
+				Major: 2024,
+				Minor: 7,
+				Build: 1,
+			},
+			false,
+		},
+		{
+			"2020.W53.0",
+			&version.Version{
+				Major: 2020,
+				Minor: 53,
+			},
+			false,
+		},
+		{
+			"2023.W53.0",
+			nil,
+			true,
+		},
+		{
+			"2023.W00.0",
+			nil,
+			true,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestZeroPadded(t *testing.T) {
+	format := "05.04.003"
+
+	cases := []tc{
+		{
+			"01.02.003",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			false,
+		},
+		{
+			"1.2.3",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			false,
+		},
+		{
+			"1.2.0003",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			false,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestStrictZeroPadded(t *testing.T) {
+	format := "05.04.003"
+
+	cases := []tc{
+		{
+			"01.02.003",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			false,
+		},
+		{
+			"10.20.1234",
+			&version.Version{
+				Major: 10,
+				Minor: 20,
+				Patch: 1234,
+			},
+			false,
+		},
+		{
+			"1.02.003",
+			nil,
+			true,
+		},
+		{
+			"01.02.03",
+			nil,
+			true,
+		},
+		{
+			"01.02.0003",
+			nil,
+			true,
+		},
+	}
+
+	testStrict(t, format, cases)
+}
+
+func TestStrictMajorMinor(t *testing.T) {
+	format := "5.4$.3"
+
+	cases := []tc{
+		{
+			"1.2",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+			},
+			false,
+		},
+		{
+			"1.0.10",
+			&version.Version{
+				Major: 1,
+				Patch: 10,
+			},
+			false,
+		},
+		{
+			"1.02",
+			nil,
+			true,
+		},
+		{
+			"01.2",
+			nil,
+			true,
+		},
+		{
+			"1.2.00",
+			nil,
+			true,
+		},
+	}
+
+	testStrict(t, format, cases)
+}
+
+func TestStrictCalendar(t *testing.T) {
+	format := "YY.0M"
+
+	cases := []tc{
+		{
+			"23.04",
+			&version.Version{
+				Major: 2023,
+				Minor: 4,
+			},
+			false,
+		},
+		{
+			"23.4",
+			nil,
+			true,
+		},
+		{
+			"023.04",
+			nil,
+			true,
+		},
+	}
+
+	testStrict(t, format, cases)
+}
+
+func TestLayoutNumberField(t *testing.T) {
+	for _, format := range []string{"5.0", "5.4.6", "10.4"} {
+		if _, err := version.Parse(format, "1.2"); err == nil {
+			t.Errorf("unknown number field accepted in layout: %s", format)
+		}
+	}
+}
+
+func TestQuotedLiteral(t *testing.T) {
+	format := "'release-'5.4.3"
+
+	cases := []tc{
+		{
+			"release-1.2.3",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			false,
+		},
+		{
+			"1.2.3",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			false,
+		},
+		{
+			"release1.2.3",
+			nil,
+			true,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	format := "!'build'1!.5"
+
+	cases := []tc{
+		{
+			"build12.3",
+			&version.Version{
+				Major: 3,
+				Build: 12,
+			},
+			false,
+		},
+		{
+			"12.3",
+			nil,
+			true,
+		},
+		{
+			"build123",
+			nil,
+			true,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestQuotedLayoutError(t *testing.T) {
+	for _, format := range []string{"'release-5.4.3", "5.4!", "!5.4", "5.4!$"} {
+		if _, err := version.Parse(format, "1.2"); err == nil {
+			t.Errorf("ill-formed layout accepted: %s", format)
+		}
+	}
+}
+
+func TestOptionalGroup(t *testing.T) {
+	format := "5.4[.3][-beta[.1]]"
+
+	cases := []tc{
+		{
+			"1.2",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+			},
+			false,
+		},
+		{
+			"1.2-rc1",
+			&version.Version{
+				Major:  1,
+				Minor:  2,
+				PreRel: version.ReleaseCandidate,
+				Build:  1,
+			},
+			false,
+		},
+		{
+			"1.2.3-rc1",
+			&version.Version{
+				Major:  1,
+				Minor:  2,
+				Patch:  3,
+				PreRel: version.ReleaseCandidate,
+				Build:  1,
+			},
+			false,
+		},
+		{
+			"1.2.3-beta",
+			&version.Version{
+				Major:  1,
+				Minor:  2,
+				Patch:  3,
+				PreRel: version.Beta,
+			},
+			false,
+		},
+		{
+			"1.2.",
+			nil,
+			true,
+		},
+		{
+			"1.2.3-beta.",
+			nil,
+			true,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestAlternatives(t *testing.T) {
+	format := "5.4.3(!'-'beta|!.beta)1"
+
+	cases := []tc{
+		{
+			"1.2.3-beta1",
+			&version.Version{
+				Major:  1,
+				Minor:  2,
+				Patch:  3,
+				PreRel: version.Beta,
+				Build:  1,
+			},
+			false,
+		},
+		{
+			"1.2.3.alpha2",
+			&version.Version{
+				Major:  1,
+				Minor:  2,
+				Patch:  3,
+				PreRel: version.Alpha,
+				Build:  2,
+			},
+			false,
+		},
+		{
+			"1.2.3alpha2",
+			nil,
+			true,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestGroupBacktracking(t *testing.T) {
+	// the group is tried first, and skipped if the rest fails
+	format := "5[.4].3"
+
+	cases := []tc{
+		{
+			"1.2.3",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			false,
+		},
+		{
+			"1.3",
+			&version.Version{
+				Major: 1,
+				Patch: 3,
+			},
+			false,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestTopLevelAlternatives(t *testing.T) {
+	format := "YYYY.0M.0D|5.4.3"
+
+	cases := []tc{
+		{
+			"2023.12.30",
+			&version.Version{
+				Major: 2023,
+				Minor: 12,
+				Patch: 30,
+			},
+			false,
+		},
+		{
+			"1.2.40",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 40,
+			},
+			false,
+		},
+	}
+
+	test(t, format, cases)
+}
+
+func TestOther(t *testing.T) {
+	format := "5.4[+o]"
+
+	cases := []tc{
+		{
+			"1.2+linux",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Other: "linux",
+			},
+			false,
+		},
+		{
+			"1.2",
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+			},
+			false,
+		},
+	}
+
+	test(t, format, cases)
+
+	v, err := version.Parse("5.4.o", "1.2.xyz")
+	if err != nil || v.Other != "xyz" {
+		t.Errorf("other expectation failed, expected: %v, actual: %+v, %v", "xyz", v, err)
+	}
+}
+
+func TestOtherAlias(t *testing.T) {
+	format := "5.4.3-beta.1.other"
+
+	v, err := version.Parse(format, "1.2.3-beta.4.linux")
+	if err != nil || v.Other != "linux" || v.Build != 4 {
+		t.Errorf("other alias expectation failed, expected: %v, actual: %+v, %v", "linux", v, err)
+	}
+	s, err := version.Format(format, v)
+	if err != nil || s != "1.2.3-beta.4.linux" {
+		t.Errorf("other alias expectation failed, expected: %v, actual: %v, %v", "1.2.3-beta.4.linux", s, err)
+	}
+
+	for _, format := range []string{"5.4.ox", "5.4.other.3", "5.4[.o.3]"} {
+		if _, err := version.Parse(format, "1.2.x"); err == nil {
+			t.Errorf("layout after other accepted: %s", format)
+		}
+	}
+}
@@ -19,31 +19,60 @@
 package version
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
 
 // format
 
+func (field Field) value(v *Version) int64 {
+	switch field {
+	case build:
+		return v.Build
+	case preRelTag:
+		return int64(v.PreRel)
+	case patch:
+		return v.Patch
+	case minor:
+		return v.Minor
+	case major:
+		return v.Major
+	default:
+		panic("unexpected field to get")
+	}
+}
+
 func (field Field) FormatField(v *Version, layout string) (string, bool) {
 	switch field {
 	case build:
-		return formatInt(v.Build), v.Build == 0
+		return formatZeroPadded(v.Build, len(layout)), v.Build == 0
 	case alphabetic_build:
 		return formatAlpha(v.Build), v.Build == 0
 	case preRelTag:
 		return formatTag(layout, v.PreRel), v.PreRel == Release
 	case patch:
-		return formatInt(v.Patch), v.Patch == 0
+		return formatZeroPadded(v.Patch, len(layout)), v.Patch == 0
 	case alphabetic_patch:
 		return formatAlpha(v.Patch), v.Patch == 0
 	case minor:
-		return formatInt(v.Minor), v.Minor == 0
+		return formatZeroPadded(v.Minor, len(layout)), v.Minor == 0
 	case major:
+		return formatZeroPadded(v.Major, len(layout)), v.Major == 0
+	case year:
 		return formatInt(v.Major), v.Major == 0
+	case short_year:
+		if v.Major == 0 {
+			return "", true // not written as -2000
+		}
+		return formatCalendar(layout, v.Major-2000), false
+	case month, week:
+		return formatCalendar(layout, v.Minor), v.Minor == 0
+	case day:
+		return formatCalendar(layout, v.Patch), v.Patch == 0
 	case other:
-		return v.Other, true
-	case fixed:
+		return v.Other, v.Other == ""
+	case fixed, required:
 		return layout, true
 	default:
 		panic("unexpected field to set")
@@ -54,6 +83,25 @@ func formatInt(val int64) string {
 	return strconv.FormatInt(val, 10)
 }
 
+func formatZeroPadded(val int64, width int) string {
+	digits := formatInt(val)
+	sign := ""
+	if val < 0 {
+		sign, digits = "-", digits[1:] // not negating val, which overflows for math.MinInt64
+	}
+	if len(sign)+len(digits) < width {
+		digits = strings.Repeat("0", width-len(sign)-len(digits)) + digits
+	}
+	return sign + digits
+}
+
+func formatCalendar(layout string, val int64) string {
+	if layout[0] == '0' {
+		return formatZeroPadded(val, 2)
+	}
+	return formatInt(val)
+}
+
 func formatAlpha(val int64) string {
 	if val == 0 {
 		return ""
@@ -129,31 +177,137 @@ func formatTag(layout string, val PreRelTag) string {
 	return strings.Join(parts, "")
 }
 
-// Format is not a stable API.
+// Format writes a version in the layout, and fails only if the layout cannot be compiled.
+//
+// The fields are written as they are, even if they are not representable in the layout:
+// negative counters are written with a minus sign, unknown tags are written as releases,
+// and fields without a token in the layout are dropped.
+// Use FormatStrict to reject such versions instead.
 func Format(layout string, version *Version) (string, error) {
-	// layout example: 5.4.3-beta.1(.other)
-	parts := make([]string, 0)
-	partsIfEnd := -1
-	for len(layout) > 0 {
-		fieldFmt, field, suffix, err := nextChunk(layout)
-		if err != nil {
-			return "", err
+	l, err := Compile(layout)
+	if err != nil {
+		return "", err
+	}
+	return l.Format(version), nil
+}
+
+// FormatStrict writes a version in the layout like Format,
+// but returns a FormatError if the version is not read back the same from the result,
+// e.g. if a counter is negative, the tag is unknown, a non-zero field is dropped,
+// or the other text is mistaken for some field when read.
+func FormatStrict(layout string, version *Version) (string, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return "", err
+	}
+	return l.FormatStrict(version)
+}
+
+// Format writes a version in the layout.
+//
+// A group is omitted if all its fields are zero, and the first alternative which writes all non-zero fields is used.
+func (l *Layout) Format(version *Version) string {
+	parts, _ := formatNodes(l.nodes, version)
+	return joinSpans(parts)
+}
+
+// FormatStrict writes a version in the layout like Format, but returns a FormatError if it is not representable.
+func (l *Layout) FormatStrict(version *Version) (string, error) {
+	fail := func(field Field, reason string) error {
+		return &FormatError{Layout: l.source, Version: *version, Field: field, Reason: reason}
+	}
+	for _, field := range []Field{major, minor, patch, build} {
+		if field.value(version) < 0 {
+			return "", fail(field, fmt.Sprintf("negative value %d", field.value(version)))
 		}
-		if field == allowEnd {
+	}
+	if version.PreRel < Alpha || version.PreRel > Release {
+		return "", fail(preRelTag, fmt.Sprintf("unknown tag %v", version.PreRel))
+	}
+	for _, field := range l.fields() {
+		if field == short_year && version.Major < 2000 {
+			return "", fail(major, fmt.Sprintf("year %d not representable as a short year", version.Major))
+		}
+	}
+
+	output := l.Format(version)
+	v2, err := l.Parse(output)
+	if err != nil {
+		return "", fail(0, fmt.Sprintf("written as %s, which is not readable: %v", output, err))
+	}
+	if dropped := droppedFields(version, v2); len(dropped) > 0 {
+		field := dropped[0].Field
+		return "", fail(field, fmt.Sprintf("%s written as %s, which is read back as %s", dropped[0].Value, output, field.valueString(v2)))
+	}
+	return output, nil
+}
+
+// FormatError tells why a version is not representable in a layout.
+type FormatError struct {
+	Layout  string
+	Version Version
+	Field   Field // the field not representable, or zero if not known
+	Reason  string
+}
+
+func (e *FormatError) Error() string {
+	if e.Field == 0 {
+		return fmt.Sprintf("version %+v not representable in layout %s: %s", e.Version, e.Layout, e.Reason)
+	}
+	return fmt.Sprintf("version %+v not representable in layout %s: %v %s", e.Version, e.Layout, e.Field, e.Reason)
+}
+
+// formatNodes writes the nodes, and reports whether all of them are omittable.
+func formatNodes(nodes []node, version *Version) ([]span, bool) {
+	return formatPatternNodes(nodes, version, 0, nil)
+}
+
+// formatPatternNodes writes the nodes like formatNodes, and writes the counter fields in the set as wildcards, spelt as read.
+func formatPatternNodes(nodes []node, version *Version, wildcard fieldSet, spelling *spelling) ([]span, bool) {
+	// layout example: 5.4.3[-beta[.1]][+other]
+	parts := make([]span, 0)
+	partsIfEnd := -1
+	omitAll := true
+	for i := range nodes {
+		n := &nodes[i]
+		var subParts []span
+		var omit bool
+		switch n.field {
+		case allowEnd:
 			if partsIfEnd == -1 {
 				partsIfEnd = len(parts)
 			}
-		} else {
-			part, omit := field.FormatField(version, fieldFmt)
-			if !omit && partsIfEnd != -1 {
-				partsIfEnd = -1
+			continue
+		case group, choice:
+			subParts, omit = formatPatternNodes(chooseAlternative(n.alts, version, wildcard), version, wildcard, spelling)
+			if n.field == group && omit {
+				subParts = nil
+			}
+		default:
+			var part string
+			if slot := slotOf(n.field); wildcard.has(slot) && isWildcardField(n.field) {
+				part, omit = formatWildcard(n.field, n.text, spelling[slot]), omittableWildcard(wildcard, slot)
+			} else {
+				part, omit = n.field.FormatField(version, n.text)
 			}
-			parts = append(parts, part)
+			subParts = []span{{node: n, text: part}}
+		}
+		if !omit {
+			partsIfEnd = -1
+			omitAll = false
 		}
-		layout = suffix
+		parts = append(parts, subParts...)
 	}
 	if partsIfEnd != -1 {
 		parts = parts[:partsIfEnd]
 	}
-	return strings.Join(parts, ""), nil
+	return parts, omitAll
+}
+
+func joinSpans(parts []span) string {
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(part.text)
+	}
+	return b.String()
 }
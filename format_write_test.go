@@ -19,6 +19,7 @@
 package version_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/gsxab/go-version"
@@ -862,3 +863,339 @@ func TestWAlphabeticBuild(t *testing.T) {
 
 	testW(t, format, cases)
 }
+
+func TestWCalendar(t *testing.T) {
+	format := "YYYY.0M.0D$.1"
+
+	cases := []tcW{
+		{
+			&version.Version{
+				Major: 2023,
+				Minor: 12,
+				Patch: 30,
+			},
+			"2023.12.30",
+			false,
+		},
+		{
+			&version.Version{
+				Major: 2024,
+				Minor: 1,
+				Patch: 2,
+				Build: 3,
+			},
+			"2024.01.02.3",
+			false,
+		},
+	}
+
+	testW(t, format, cases)
+}
+
+func TestWShortYearMonth(t *testing.T) {
+	format := "YY.MM.DD"
+
+	cases := []tcW{
+		{
+			&version.Version{
+				Major: 2023,
+				Minor: 4,
+				Patch: 5,
+			},
+			"23.4.5",
+			false,
+		},
+		{
+			&version.Version{
+				Major: 2106,
+				Minor: 11,
+				Patch: 30,
+			},
+			"106.11.30",
+			false,
+		},
+		{
+			&version.Version{
+				Minor: 4,
+				Patch: 5,
+			},
+			".4.5",
+			false,
+		},
+	}
+
+	testW(t, format, cases)
+}
+
+func TestWYearWeek(t *testing.T) {
+	format := "0Y.W0W"
+
+	cases := []tcW{
+		{
+			&version.Version{
+				Major: 2006,
+				Minor: 7,
+			},
+			"06.W07",
+			false,
+		},
+	}
+
+	testW(t, format, cases)
+}
+
+func TestWZeroPadded(t *testing.T) {
+	format := "05.04.003"
+
+	cases := []tcW{
+		{
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			"01.02.003",
+			false,
+		},
+		{
+			&version.Version{
+				Major: 10,
+				Minor: 200,
+				Patch: 1234,
+			},
+			"10.200.1234",
+			false,
+		},
+		{
+			&version.Version{},
+			"00.00.000",
+			false,
+		},
+		{
+			&version.Version{
+				Major: -1,
+				Patch: -12,
+			},
+			"-1.00.-12",
+			false,
+		},
+		{
+			&version.Version{
+				Major: math.MinInt64,
+				Minor: math.MaxInt64,
+			},
+			"-9223372036854775808.9223372036854775807.000",
+			false,
+		},
+	}
+
+	testW(t, format, cases)
+}
+
+func TestWMinInt64(t *testing.T) {
+	format := "5.4.3[-beta.1]"
+
+	cases := []tcW{
+		{
+			&version.Version{
+				Major:  math.MinInt64,
+				PreRel: version.Beta,
+				Build:  math.MinInt64,
+			},
+			"-9223372036854775808.0.0-beta.-9223372036854775808",
+			false,
+		},
+	}
+
+	testW(t, format, cases)
+}
+
+func TestWQuotedLiteral(t *testing.T) {
+	format := "'release-'5.4!.3'-o''clock'"
+
+	cases := []tcW{
+		{
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			"release-1.2.3-o'clock",
+			false,
+		},
+	}
+
+	testW(t, format, cases)
+
+	s, err := version.Format("''5", &version.Version{Major: 1})
+	if err != nil || s != "'1" {
+		t.Errorf("version expectation failed, expected: %+v, actual: %+v, %v", "'1", s, err)
+	}
+}
+
+func TestWOptionalGroup(t *testing.T) {
+	format := "5.4[.3][-beta[.1]]"
+
+	cases := []tcW{
+		{
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+			},
+			"1.2",
+			false,
+		},
+		{
+			&version.Version{
+				Major:  1,
+				Minor:  2,
+				PreRel: version.ReleaseCandidate,
+				Build:  1,
+			},
+			"1.2-rc.1",
+			false,
+		},
+		{
+			&version.Version{
+				Major:  1,
+				Minor:  2,
+				Patch:  3,
+				PreRel: version.Beta,
+			},
+			"1.2.3-beta",
+			false,
+		},
+	}
+
+	testW(t, format, cases)
+}
+
+func TestWAlternatives(t *testing.T) {
+	format := "(5.4.3|YYYY.0M.0D.1)"
+
+	cases := []tcW{
+		{
+			&version.Version{
+				Major: 1,
+				Minor: 2,
+				Patch: 3,
+			},
+			"1.2.3",
+			false,
+		},
+		{
+			&version.Version{
+				Major: 2023,
+				Minor: 12,
+				Patch: 30,
+				Build: 1,
+			},
+			"2023.12.30.1",
+			false,
+		},
+	}
+
+	testW(t, format, cases)
+}
+
+func TestWStrict(t *testing.T) {
+	cases := []struct {
+		Layout   string
+		Version  *version.Version
+		Expected string
+		Field    version.Field
+	}{
+		{
+			"5.4.3-beta.1",
+			&version.Version{Major: 1, Minor: 2, Patch: 3, PreRel: version.Beta, Build: 4},
+			"1.2.3-beta.4",
+			0,
+		},
+		{
+			"5.4[.3]",
+			&version.Version{Major: 1, Minor: 2},
+			"1.2",
+			0,
+		},
+		{
+			"5.4.3",
+			&version.Version{Major: 1, Minor: -2, Patch: 3},
+			"",
+			version.MinorField,
+		},
+		{
+			"5.4.3-beta.1",
+			&version.Version{Major: 1, Minor: 2, Patch: 3, PreRel: 5},
+			"",
+			version.PreRelField,
+		},
+		{
+			"5.4.3",
+			&version.Version{Major: 1, Minor: 2, Patch: 3, Build: 4},
+			"",
+			version.BuildField,
+		},
+		{
+			"5.4.3",
+			&version.Version{Major: 1, Minor: 2, Patch: 3, PreRel: version.Alpha},
+			"",
+			version.PreRelField,
+		},
+		{
+			"5.4o",
+			&version.Version{Major: 1, Minor: 2, Other: "5"},
+			"",
+			version.MinorField,
+		},
+		{
+			"5.4",
+			&version.Version{Major: 1, Minor: 2, Other: "linux"},
+			"",
+			version.OtherField,
+		},
+		{
+			"5.4[+o]",
+			&version.Version{Major: 1, Minor: 2, Other: "linux"},
+			"1.2+linux",
+			0,
+		},
+		{
+			"0Y.0M",
+			&version.Version{Major: 2006, Minor: 1},
+			"06.01",
+			0,
+		},
+		{
+			"0Y.0M",
+			&version.Version{Major: 1999, Minor: 1},
+			"",
+			version.MajorField,
+		},
+		{
+			"0Y.0M",
+			&version.Version{Minor: 1},
+			"",
+			version.MajorField,
+		},
+		{
+			"5.4+o",
+			&version.Version{Major: 1, Minor: 2, Other: "linux"},
+			"1.2+linux",
+			0,
+		},
+	}
+
+	for _, c := range cases {
+		s, err := version.FormatStrict(c.Layout, c.Version)
+		if c.Field == 0 {
+			if err != nil || s != c.Expected {
+				t.Errorf("version expectation failed, expected: %v, actual: %v, %v; layout: %v, input: %+v", c.Expected, s, err, c.Layout, c.Version)
+			}
+			continue
+		}
+		formatErr, ok := err.(*version.FormatError)
+		if !ok || formatErr.Field != c.Field {
+			t.Errorf("error expectation failed, expected: %v, actual: %v; layout: %v, input: %+v", c.Field, err, c.Layout, c.Version)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package httpversion provides net/http middleware for API versioning,
+// which reads the version requested from a header, the path or the query,
+// and resolves it against the supported versions.
+package httpversion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gsxab/go-version"
+)
+
+// Extractor returns the version string requested, or false if there is none.
+type Extractor func(r *http.Request) (string, bool)
+
+// Header extracts the version from a header, e.g. `Accept-Version: 1.3`.
+func Header(name string) Extractor {
+	return func(r *http.Request) (string, bool) {
+		s := r.Header.Get(name)
+		return s, s != ""
+	}
+}
+
+// Path extracts the version from the first segment of the path, e.g. `v2` from `/v2/users` in a layout like `v5`.
+// A path whose first segment is not a version in the layout, like `/users`, requests no version,
+// so that the default version applies to it.
+func Path(layout *version.Layout) Extractor {
+	return func(r *http.Request) (string, bool) {
+		segment := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+		if _, err := layout.Parse(segment); err != nil {
+			return "", false
+		}
+		return segment, true
+	}
+}
+
+// Query extracts the version from a query parameter, e.g. `?api-version=2023-12-30`.
+func Query(name string) Extractor {
+	return func(r *http.Request) (string, bool) {
+		s := r.URL.Query().Get(name)
+		return s, s != ""
+	}
+}
+
+// Match is how a version requested is resolved against the supported versions.
+type Match int
+
+const (
+	// Exact resolves a version to the same supported version.
+	Exact Match = iota
+	// NearestLower resolves a version to the greatest supported version not greater than it.
+	NearestLower
+	// BestSatisfying reads a constraint instead, like `^1.2`, and resolves it to the greatest supported version satisfying it.
+	BestSatisfying
+)
+
+// Config configures the middleware.
+type Config struct {
+	Layout    *version.Layout    // the layout of the versions requested
+	Extract   Extractor          // where the version is requested
+	Supported []*version.Version // the versions supported
+	Match     Match
+	Default   *version.Version // the version used if none is requested, or nil to reject such requests
+}
+
+// Versions is the version requested in a request and the one resolved to.
+type Versions struct {
+	Requested *version.Version // the version requested, or nil if none is requested, or a constraint is requested
+	Resolved  *version.Version // the supported version resolved to, or the default if none is requested
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the versions of a request.
+func NewContext(ctx context.Context, versions Versions) context.Context {
+	return context.WithValue(ctx, contextKey{}, versions)
+}
+
+// FromContext returns the versions stored by the middleware, or false if there are none.
+func FromContext(ctx context.Context) (Versions, bool) {
+	versions, ok := ctx.Value(contextKey{}).(Versions)
+	return versions, ok
+}
+
+// Middleware returns a middleware resolving the version requested, and storing both in the request context for FromContext.
+//
+// A request with a version not readable, or without a version if there is no default, is rejected with 400 Bad Request,
+// and one with a version not supported is rejected with 406 Not Acceptable, both listing the supported versions.
+func Middleware(config Config) func(http.Handler) http.Handler {
+	supported := make([]string, len(config.Supported))
+	for i, v := range config.Supported {
+		supported[i] = config.Layout.Format(v)
+	}
+	reject := func(w http.ResponseWriter, code int, reason string) {
+		http.Error(w, fmt.Sprintf("%s, supported versions: %s", reason, strings.Join(supported, ", ")), code)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested, ok := config.Extract(r)
+			if !ok && config.Default == nil {
+				reject(w, http.StatusBadRequest, "version required")
+				return
+			}
+			versions := Versions{Resolved: config.Default}
+			if ok {
+				requestedVersion, resolved, err := config.resolve(requested)
+				if err != nil {
+					reject(w, http.StatusBadRequest, fmt.Sprintf("version %s not readable", requested))
+					return
+				}
+				if resolved == nil {
+					reject(w, http.StatusNotAcceptable, fmt.Sprintf("version %s not supported", requested))
+					return
+				}
+				versions = Versions{Requested: requestedVersion, Resolved: resolved}
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), versions)))
+		})
+	}
+}
+
+// resolve returns the version read from a version string requested, nil if it is a constraint,
+// and the supported version it is resolved to, nil if none, or an error if it is not readable.
+func (config *Config) resolve(requested string) (*version.Version, *version.Version, error) {
+	if config.Match == BestSatisfying {
+		c, err := config.Layout.ParseConstraint(requested)
+		if err != nil {
+			return nil, nil, err
+		}
+		var best *version.Version
+		for _, v := range config.Supported {
+			if c.Check(v) && (best == nil || best.LT(v)) {
+				best = v
+			}
+		}
+		return nil, best, nil
+	}
+
+	requestedVersion, err := config.Layout.Parse(requested)
+	if err != nil {
+		return nil, nil, err
+	}
+	var best *version.Version
+	for _, v := range config.Supported {
+		switch {
+		case v.EQ(requestedVersion):
+			return requestedVersion, v, nil
+		case config.Match == NearestLower && v.LT(requestedVersion) && (best == nil || best.LT(v)):
+			best = v
+		}
+	}
+	return requestedVersion, best, nil
+}
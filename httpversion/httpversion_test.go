@@ -0,0 +1,171 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpversion_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gsxab/go-version"
+	"github.com/gsxab/go-version/httpversion"
+)
+
+func mustParse(t *testing.T, layout *version.Layout, versionString string) *version.Version {
+	v, err := layout.Parse(versionString)
+	if err != nil {
+		t.Fatalf("unexpected error: %v; version: %v", err, versionString)
+	}
+	return v
+}
+
+// server returns a handler behind the middleware, which writes the version resolved,
+// and the version requested after a slash if one is read.
+func server(t *testing.T, config httpversion.Config, supported ...string) http.Handler {
+	for _, s := range supported {
+		config.Supported = append(config.Supported, mustParse(t, config.Layout, s))
+	}
+	return httpversion.Middleware(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		versions, ok := httpversion.FromContext(r.Context())
+		if !ok {
+			t.Errorf("version expected in the context")
+		}
+		fmt.Fprint(w, config.Layout.Format(versions.Resolved))
+		if versions.Requested != nil {
+			fmt.Fprint(w, "/"+config.Layout.Format(versions.Requested))
+		}
+	}))
+}
+
+func TestMiddleware(t *testing.T) {
+	semver := version.MustCompile("[v]5$.4$.3")
+	calendar := version.MustCompile("YYYY-0M-0D")
+	cases := []struct {
+		Config    httpversion.Config
+		Supported []string
+		Request   func(r *http.Request)
+		Code      int
+		Expected  string
+	}{
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Header("Accept-Version"), Match: httpversion.Exact},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.Header.Set("Accept-Version", "1.3") },
+			http.StatusOK, "1.3/1.3",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Header("Accept-Version"), Match: httpversion.Exact},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.Header.Set("Accept-Version", "1.4") },
+			http.StatusNotAcceptable, "version 1.4 not supported, supported versions: 1.2, 1.3, 2\n",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Header("Accept-Version"), Match: httpversion.Exact},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.Header.Set("Accept-Version", "latest") },
+			http.StatusBadRequest, "version latest not readable, supported versions: 1.2, 1.3, 2\n",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Header("Accept-Version"), Match: httpversion.Exact},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) {},
+			http.StatusBadRequest, "version required, supported versions: 1.2, 1.3, 2\n",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Header("Accept-Version"), Match: httpversion.Exact, Default: &version.Version{Major: 2}},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) {},
+			http.StatusOK, "2",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Path(semver), Match: httpversion.NearestLower},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.URL.Path = "/v1.9/users" },
+			http.StatusOK, "1.3/1.9",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Path(semver), Match: httpversion.NearestLower},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.URL.Path = "/v3/users" },
+			http.StatusOK, "2/3",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Path(semver), Match: httpversion.NearestLower},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.URL.Path = "/v1/users" },
+			http.StatusNotAcceptable, "version v1 not supported, supported versions: 1.2, 1.3, 2\n",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Path(semver), Match: httpversion.NearestLower, Default: &version.Version{Major: 2}},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.URL.Path = "/users" },
+			http.StatusOK, "2",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Path(semver), Match: httpversion.NearestLower},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.URL.Path = "/users" },
+			http.StatusBadRequest, "version required, supported versions: 1.2, 1.3, 2\n",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Header("Accept-Version"), Match: httpversion.BestSatisfying},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.Header.Set("Accept-Version", "^1.2") },
+			http.StatusOK, "1.3",
+		},
+		{
+			httpversion.Config{Layout: semver, Extract: httpversion.Header("Accept-Version"), Match: httpversion.BestSatisfying},
+			[]string{"1.2", "1.3", "2.0"},
+			func(r *http.Request) { r.Header.Set("Accept-Version", ">=2.1") },
+			http.StatusNotAcceptable, "version >=2.1 not supported, supported versions: 1.2, 1.3, 2\n",
+		},
+		{
+			httpversion.Config{Layout: calendar, Extract: httpversion.Query("api-version"), Match: httpversion.NearestLower},
+			[]string{"2023-01-01", "2023-12-30", "2024-06-01"},
+			func(r *http.Request) { r.URL.RawQuery = "api-version=2024-01-15" },
+			http.StatusOK, "2023-12-30/2024-01-15",
+		},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/users", nil)
+		c.Request(r)
+		w := httptest.NewRecorder()
+		server(t, c.Config, c.Supported...).ServeHTTP(w, r)
+		if w.Code != c.Code {
+			t.Errorf("status expectation failed, expected: %v, actual: %v; request: %v", c.Code, w.Code, r.URL)
+		}
+		if body := w.Body.String(); body != c.Expected {
+			t.Errorf("body expectation failed, expected: %q, actual: %q; request: %v", c.Expected, body, r.URL)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, ok := httpversion.FromContext(r.Context()); ok {
+		t.Errorf("version not expected in a context without one")
+	}
+	versions := httpversion.Versions{Requested: &version.Version{Major: 1, Minor: 5}, Resolved: &version.Version{Major: 1}}
+	ctx := httpversion.NewContext(r.Context(), versions)
+	if actual, ok := httpversion.FromContext(ctx); !ok || actual != versions {
+		t.Errorf("context expectation failed, expected: %v, actual: %v", versions, actual)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// displayLayout writes versions without a layout of their own, e.g. the bounds of an Interval.
+var displayLayout = MustCompile("5.4.3[-beta][.1]")
+
+// Interval is a half-open range of versions, from Lower inclusive to Upper exclusive.
+// A nil bound is unbounded, so the zero Interval contains all versions.
+type Interval struct {
+	Lower *Version
+	Upper *Version
+}
+
+// compareVersions returns -1, 0 or 1 if a version is less than, equal to or greater than another one, as LT and EQ do.
+func compareVersions(v *Version, v2 *Version) int {
+	switch {
+	case v.LT(v2):
+		return -1
+	case v.EQ(v2):
+		return 0
+	default:
+		return 1
+	}
+}
+
+// bound returns a copy of a version without the other text, which is not compared.
+func bound(v *Version) *Version {
+	if v == nil {
+		return nil
+	}
+	b := *v
+	b.Other = ""
+	return &b
+}
+
+// successor returns the least version greater than a version, i.e. the next build.
+func successor(v *Version) *Version {
+	s := bound(v)
+	if s.Build == math.MaxInt64 {
+		s.PreRel++
+		s.Build = math.MinInt64
+	} else {
+		s.Build++
+	}
+	return s
+}
+
+// Point returns the interval containing only the version.
+func Point(v *Version) Interval {
+	return Interval{Lower: bound(v), Upper: successor(v)}
+}
+
+// Contains reports whether the version is in the interval.
+func (i Interval) Contains(v *Version) bool {
+	return (i.Lower == nil || !v.LT(i.Lower)) && (i.Upper == nil || v.LT(i.Upper))
+}
+
+// IsEmpty reports whether the interval contains no version.
+func (i Interval) IsEmpty() bool {
+	return i.Lower != nil && i.Upper != nil && !i.Lower.LT(i.Upper)
+}
+
+// String returns the interval as a constraint, e.g. `>=1.2.0, <2.0.0`, `1.2.0` for a single version, or `*` for all versions.
+func (i Interval) String() string {
+	if i.Lower != nil && i.Upper != nil && i.Upper.EQ(successor(i.Lower)) {
+		return displayLayout.Format(i.Lower)
+	}
+	parts := make([]string, 0, 2)
+	if i.Lower != nil {
+		parts = append(parts, ">="+displayLayout.Format(i.Lower))
+	}
+	if i.Upper != nil {
+		parts = append(parts, "<"+displayLayout.Format(i.Upper))
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lowerLess reports whether a lower bound is less than another one, where nil is the least.
+func lowerLess(b *Version, b2 *Version) bool {
+	return b2 != nil && (b == nil || b.LT(b2))
+}
+
+// upperLess reports whether an upper bound is less than another one, where nil is the greatest.
+func upperLess(b *Version, b2 *Version) bool {
+	return b != nil && (b2 == nil || b.LT(b2))
+}
+
+// intersect returns the intersection of two intervals, which may be empty.
+func (i Interval) intersect(i2 Interval) Interval {
+	result := i
+	if lowerLess(result.Lower, i2.Lower) {
+		result.Lower = i2.Lower
+	}
+	if upperLess(i2.Upper, result.Upper) {
+		result.Upper = i2.Upper
+	}
+	return result
+}
+
+// normalizeIntervals sorts the intervals, drops the empty ones, and merges the overlapping or adjacent ones.
+func normalizeIntervals(intervals []Interval) []Interval {
+	sorted := make([]Interval, 0, len(intervals))
+	for _, i := range intervals {
+		if !i.IsEmpty() {
+			sorted = append(sorted, i)
+		}
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		return lowerLess(sorted[a].Lower, sorted[b].Lower)
+	})
+	merged := make([]Interval, 0, len(sorted))
+	for _, i := range sorted {
+		if n := len(merged); n > 0 && (merged[n-1].Upper == nil || i.Lower == nil || !merged[n-1].Upper.LT(i.Lower)) {
+			if upperLess(merged[n-1].Upper, i.Upper) {
+				merged[n-1].Upper = i.Upper
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
@@ -0,0 +1,169 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+	"math"
+)
+
+// Iterator yields successive versions from a version to another, in the order of versions.
+//
+//	it := version.Range(from, to, version.PatchField)
+//	for it.Next() {
+//		v := it.Version()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	next    *Version // the version to yield next, or nil if none
+	to      *Version
+	step    Field
+	current *Version
+	err     error
+}
+
+// Range returns an iterator yielding the versions from a version to another, both inclusive,
+// incrementing the field as a counter each step, which resets the less significant fields,
+// e.g. 1.2.3, 1.2.4, ..., 1.2.9 by the patch from 1.2.3 to 1.2.9, or 1.2.3, 1.3.0, 1.4.0 by the minor from 1.2.3 to 1.4.5.
+// Counters have no limit, so a step never carries into a more significant field,
+// and the versions must be the same in the fields more significant than a counter stepped,
+// e.g. iterating from 1.0 to 2.0 by the minor yields nothing, and Err returns an error, since it would never reach 2.0.
+// Because alphabetic counters are stored as numbers, builds from `a` to `zz` are also yielded, as 1 to 702.
+// The pre-release tag is stepped from alpha to beta to release candidate to release.
+//
+// The other text is kept in the first version, and dropped in others. It panics if the field is not a counter or the tag.
+func Range(from *Version, to *Version, step Field) *Iterator {
+	switch step {
+	case major, minor, patch, preRelTag, build:
+	default:
+		panic("unexpected field to step")
+	}
+	it := &Iterator{to: to, step: step}
+	if to.LT(from) {
+		return it
+	}
+	if step != preRelTag {
+		// a stepped tag ends at a release, but a counter only ends at the same more significant fields
+		for _, field := range sortKeyFields {
+			if field == step {
+				break
+			}
+			if field.value(from) != field.value(to) {
+				it.err = fmt.Errorf("%v differs between the ends, never reached by stepping the %v", field, step)
+				return it
+			}
+		}
+	}
+	first := *from
+	it.next = &first
+	return it
+}
+
+// Next advances to the next version, and reports whether there is one.
+func (it *Iterator) Next() bool {
+	if it.next == nil {
+		it.current = nil
+		return false
+	}
+	it.current = it.next
+	it.next = it.stepped(it.current)
+	return true
+}
+
+// Err returns the error if the range is rejected, or nil.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Version returns the current version, which is not modified by later steps.
+func (it *Iterator) Version() *Version {
+	return it.current
+}
+
+// stepped returns the version a step after a version, or nil if it is greater than the end, or not representable.
+func (it *Iterator) stepped(v *Version) *Version {
+	val := it.step.value(v)
+	if val == math.MaxInt64 || it.step == preRelTag && PreRelTag(val) >= Release {
+		return nil
+	}
+	next := *v
+	next.Other = ""
+	it.step.SetField(&next, val+1)
+	for field := it.step - 1; field >= build; field-- {
+		field.SetField(&next, 0)
+	}
+	if it.to.LT(&next) {
+		return nil
+	}
+	return &next
+}
+
+// Delta is the difference between two versions per field, i.e. the value of each field in a version minus that in another one.
+// The difference of tags is the count of steps between them, e.g. 2 from alpha to release candidate.
+type Delta struct {
+	Major  int64
+	Minor  int64
+	Patch  int64
+	PreRel int64
+	Build  int64
+}
+
+// Distance returns the difference from a version to another one per field, e.g. {0, 1, -3, 0, 0} from 1.2.3 to 1.3.0.
+// The other text is not compared.
+func Distance(from *Version, to *Version) Delta {
+	return Delta{
+		Major:  to.Major - from.Major,
+		Minor:  to.Minor - from.Minor,
+		Patch:  to.Patch - from.Patch,
+		PreRel: int64(to.PreRel - from.PreRel),
+		Build:  to.Build - from.Build,
+	}
+}
+
+// Get returns the difference of a field, or panics if the field is not a counter or the tag.
+func (d Delta) Get(field Field) int64 {
+	switch field {
+	case major:
+		return d.Major
+	case minor:
+		return d.Minor
+	case patch:
+		return d.Patch
+	case preRelTag:
+		return d.PreRel
+	case build:
+		return d.Build
+	default:
+		panic("unexpected field to get")
+	}
+}
+
+// Significant returns the most significant field which differs, e.g. the minor from 1.2.3 to 1.3.0, or 0 if no field differs,
+// which tells how far apart two versions are.
+func (d Delta) Significant() Field {
+	for _, field := range sortKeyFields {
+		if d.Get(field) != 0 {
+			return field
+		}
+	}
+	return 0
+}
@@ -0,0 +1,136 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestRange(t *testing.T) {
+	semver := "5.4.3[-beta[.1]]"
+	alphabetic := "5.4z"
+	cases := []struct {
+		Layout   string
+		From     string
+		To       string
+		Step     version.Field
+		Expected string
+	}{
+		{semver, "1.2.3", "1.2.9", version.PatchField, "1.2.3 1.2.4 1.2.5 1.2.6 1.2.7 1.2.8 1.2.9"},
+		{semver, "1.2.3", "1.4.5", version.MinorField, "1.2.3 1.3.0 1.4.0"},
+		{semver, "1.2.3", "1.2.3", version.MajorField, "1.2.3"},
+		{semver, "1.2.3", "1.2.2", version.PatchField, ""},
+		{semver, "1.0.0-alpha.2", "1.0.0", version.PreRelField, "1.0.0-alpha.2 1.0.0-beta 1.0.0-rc 1.0.0"},
+		{semver, "1.0.0-rc.1", "1.0.0-rc.3", version.BuildField, "1.0.0-rc.1 1.0.0-rc.2 1.0.0-rc.3"},
+		{semver, "1.0.0-rc.1", "2.0.0", version.PreRelField, "1.0.0-rc.1 1.0.0"},
+		{semver, "1.0.0", "2.0.0", version.MinorField, ""},
+		{semver, "1.2.3", "1.4.5", version.PatchField, ""},
+		{alphabetic, "1.2x", "1.2ab", version.BuildField, "1.2x 1.2y 1.2z 1.2aa 1.2ab"},
+	}
+
+	for _, c := range cases {
+		from, to := mustParse(t, c.Layout, c.From), mustParse(t, c.Layout, c.To)
+		layout := version.MustCompile(c.Layout)
+		actual := make([]string, 0)
+		for it := version.Range(from, to, c.Step); it.Next(); {
+			actual = append(actual, layout.Format(it.Version()))
+		}
+		if strings.Join(actual, " ") != c.Expected {
+			t.Errorf("range expectation failed, expected: %v, actual: %v; from: %v, to: %v, step: %v", c.Expected, actual, c.From, c.To, c.Step)
+		}
+	}
+}
+
+func TestRangeAlphabetic(t *testing.T) {
+	layout := version.MustCompile("5z")
+	it := version.Range(mustParse(t, "5z", "1a"), mustParse(t, "5z", "1zz"), version.BuildField)
+	count := 0
+	var last string
+	for it.Next() {
+		count++
+		last = layout.Format(it.Version())
+	}
+	if count != 702 || last != "1zz" {
+		t.Errorf("range expectation failed, expected: %v, %v, actual: %v, %v", 702, "1zz", count, last)
+	}
+	if it.Next() || it.Version() != nil {
+		t.Errorf("range expected to stay ended")
+	}
+}
+
+func TestRangeUnreachable(t *testing.T) {
+	from := &version.Version{Major: 1}
+	to := &version.Version{Major: 2}
+	it := version.Range(from, to, version.MinorField)
+	count := 0
+	for it.Next() && count < 1000 {
+		count++
+	}
+	if count != 0 || it.Err() == nil {
+		t.Errorf("range expectation failed, expected: %v versions and an error, actual: %v, %v", 0, count, it.Err())
+	}
+	if it := version.Range(from, &version.Version{Major: 1, Minor: 3}, version.MinorField); !it.Next() || it.Err() != nil {
+		t.Errorf("unexpected error: %v", it.Err())
+	}
+}
+
+func TestRangeOverflow(t *testing.T) {
+	from := &version.Version{Major: 1, Patch: math.MaxInt64 - 1}
+	to := &version.Version{Major: 1, Patch: math.MaxInt64, Build: 5}
+	count := 0
+	for it := version.Range(from, to, version.PatchField); it.Next(); {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("range expectation failed, expected: %v, actual: %v", 2, count)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	semver := "5.4.3[-beta.1]"
+	cases := []struct {
+		From        string
+		To          string
+		Expected    version.Delta
+		Significant version.Field
+	}{
+		{"1.2.3", "1.3.0", version.Delta{Minor: 1, Patch: -3}, version.MinorField},
+		{"2.0.0", "1.9.9", version.Delta{Major: -1, Minor: 9, Patch: 9}, version.MajorField},
+		{"1.0.0-alpha.1", "1.0.0-rc.3", version.Delta{PreRel: 2, Build: 2}, version.PreRelField},
+		{"1.0.0-rc.1", "1.0.0-rc.3", version.Delta{Build: 2}, version.BuildField},
+		{"1.2.3", "1.2.3", version.Delta{}, 0},
+	}
+
+	for _, c := range cases {
+		d := version.Distance(mustParse(t, semver, c.From), mustParse(t, semver, c.To))
+		if d != c.Expected {
+			t.Errorf("distance expectation failed, expected: %+v, actual: %+v; from: %v, to: %v", c.Expected, d, c.From, c.To)
+		}
+		if s := d.Significant(); s != c.Significant {
+			t.Errorf("significant field expectation failed, expected: %v, actual: %v; from: %v, to: %v", c.Significant, s, c.From, c.To)
+		}
+		if d.Get(version.PatchField) != d.Patch {
+			t.Errorf("patch expectation failed, expected: %v, actual: %v", d.Patch, d.Get(version.PatchField))
+		}
+	}
+}
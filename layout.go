@@ -0,0 +1,224 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+)
+
+// Layout is a compiled layout, which can be used to parse and format versions repeatedly.
+type Layout struct {
+	source string
+	nodes  []node
+}
+
+// node is a chunk of a layout, or a group of alternative sequences of chunks.
+type node struct {
+	text  string // the chunk, unquoted for literals
+	field Field
+	alts  [][]node // alternatives, for groups and choices only
+}
+
+// Compile compiles a layout.
+//
+// Besides the tokens, a layout may contain optional groups like `5.4[.3]`, and alternatives like `5.4.3(-b|.b)1`.
+// An optional group may also contain alternatives, like `5.4.3[-b|.b]`.
+//
+// A layout containing placeholders in braces, like `v{major}.{minor}[.{patch}]{-pre}{.build}`,
+// is in the named syntax instead, where other letters are literals.
+// See Named for the placeholders.
+func Compile(layout string) (*Layout, error) {
+	next := nextNode
+	if isNamed(layout) {
+		next = nextNamedNode
+	}
+	alts, rest, err := compileAlternatives(layout, next)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("unexpected %c in layout: %s", rest[0], layout)
+	}
+	l := &Layout{source: layout}
+	if len(alts) == 1 {
+		l.nodes = alts[0]
+	} else {
+		l.nodes = []node{{text: layout, field: choice, alts: alts}}
+	}
+	return l, nil
+}
+
+// MustCompile is like Compile but panics if the layout cannot be compiled.
+func MustCompile(layout string) *Layout {
+	l, err := Compile(layout)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// String returns the source of the layout.
+func (l *Layout) String() string {
+	return l.source
+}
+
+func isStructural(c byte) bool {
+	switch c {
+	case '[', ']', '(', ')', '|':
+		return true
+	default:
+		return false
+	}
+}
+
+// nodeFunc compiles the next node, which is not a group, from the layout, and returns the rest.
+type nodeFunc func(layout string) (node, string, error)
+
+func nextNode(layout string) (node, string, error) {
+	text, field, suffix, err := nextChunk(layout)
+	if err != nil {
+		return node{}, "", err
+	}
+	return node{text: text, field: field}, suffix, nil
+}
+
+// compileSequence compiles nodes until the end of the layout, a closing bracket, or a bar, which is left in the rest.
+func compileSequence(layout string, next nodeFunc) ([]node, string, error) {
+	nodes := make([]node, 0)
+	for len(layout) > 0 {
+		switch layout[0] {
+		case ']', ')', '|':
+			return nodes, layout, nil
+		case '[', '(':
+			field, closing := group, byte(']')
+			if layout[0] == '(' {
+				field, closing = choice, ')'
+			}
+			alts, rest, err := compileAlternatives(layout[1:], next)
+			if err != nil {
+				return nil, "", err
+			}
+			if len(rest) == 0 || rest[0] != closing {
+				return nil, "", fmt.Errorf("unclosed %c in layout: %s", layout[0], layout)
+			}
+			nodes = append(nodes, node{text: layout[:len(layout)-len(rest)+1], field: field, alts: alts})
+			layout = rest[1:]
+			continue
+		}
+		n, suffix, err := next(layout)
+		if err != nil {
+			return nil, "", err
+		}
+		if n.field == other && len(suffix) > 0 && suffix[0] != ']' && suffix[0] != ')' && suffix[0] != '|' {
+			// the other text reads all the rest, so nothing after it would be read
+			return nil, "", fmt.Errorf("unexpected layout after other: %s", suffix)
+		}
+		nodes = append(nodes, n)
+		layout = suffix
+	}
+	return nodes, layout, nil
+}
+
+func compileAlternatives(layout string, next nodeFunc) ([][]node, string, error) {
+	alts := make([][]node, 0)
+	for {
+		nodes, rest, err := compileSequence(layout, next)
+		if err != nil {
+			return nil, "", err
+		}
+		alts = append(alts, nodes)
+		if len(rest) == 0 || rest[0] != '|' {
+			return alts, rest, nil
+		}
+		layout = rest[1:]
+	}
+}
+
+// fields returns all fields in the layout, including those in groups.
+func (l *Layout) fields() []Field {
+	fields := make([]Field, 0)
+	var walk func(nodes []node)
+	walk = func(nodes []node) {
+		for _, n := range nodes {
+			if n.alts != nil {
+				for _, alt := range n.alts {
+					walk(alt)
+				}
+			} else {
+				fields = append(fields, n.field)
+			}
+		}
+	}
+	walk(l.nodes)
+	return fields
+}
+
+// fieldSet is a set of fields.
+type fieldSet uint64
+
+func (s fieldSet) has(field Field) bool {
+	return s&(1<<uint(field)) != 0
+}
+
+func (s fieldSet) with(field Field) fieldSet {
+	return s | 1<<uint(field)
+}
+
+// slots returns the counter fields written by the nodes.
+func slots(nodes []node) fieldSet {
+	var s fieldSet
+	for _, n := range nodes {
+		for _, alt := range n.alts {
+			s |= slots(alt)
+		}
+		s = s.with(slotOf(n.field))
+	}
+	return s
+}
+
+// nonZeroSlots returns the counter fields of the version which are not zero.
+func nonZeroSlots(v *Version) fieldSet {
+	var s fieldSet
+	for _, field := range []Field{build, preRelTag, patch, minor, major} {
+		if field.value(v) != 0 {
+			s = s.with(field)
+		}
+	}
+	if v.Other != "" {
+		s = s.with(other)
+	}
+	return s
+}
+
+// chooseAlternative returns the first alternative writing all non-zero fields of the version,
+// and the wildcards not omittable, or the first one if none.
+func chooseAlternative(alts [][]node, v *Version, wildcard fieldSet) []node {
+	want := nonZeroSlots(v)
+	for field := build; field <= major; field++ {
+		if wildcard.has(field) && !omittableWildcard(wildcard, field) {
+			want = want.with(field)
+		}
+	}
+	for _, alt := range alts {
+		if slots(alt)&want == want {
+			return alt
+		}
+	}
+	return alts[0]
+}
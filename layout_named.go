@@ -0,0 +1,339 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// named layout syntax, e.g. v{major}.{minor}[.{patch}]{-pre}{.build}
+
+// isNamed reports whether a layout contains a placeholder out of quotes.
+func isNamed(layout string) bool {
+	quoted := false
+	for i := 0; i < len(layout); i++ {
+		switch layout[i] {
+		case '\'':
+			quoted = !quoted
+		case '{':
+			if !quoted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isPlaceholderMark(c byte) bool {
+	return c == '-' || c == '.' || c == '_' || c == '+' || c == '~'
+}
+
+func nextNamedNode(layout string) (node, string, error) {
+	switch layout[0] {
+	case '{':
+		end := strings.IndexByte(layout, '}')
+		if end == -1 {
+			return node{}, "", fmt.Errorf("unclosed { in layout: %s", layout)
+		}
+		n, err := compilePlaceholder(layout[1:end])
+		if err != nil {
+			return node{}, "", err
+		}
+		return n, layout[end+1:], nil
+	case '\'', '$':
+		return nextNode(layout)
+	case '!':
+		if len(layout) == 1 {
+			return node{}, "", errors.New("required mark at the end of layout")
+		}
+		if isStructural(layout[1]) {
+			return node{}, "", fmt.Errorf("required mark before a non-literal: %s", layout[:2])
+		}
+		n, suffix, err := nextNamedNode(layout[1:])
+		if err != nil {
+			return node{}, "", err
+		}
+		if n.field != fixed {
+			return node{}, "", fmt.Errorf("required mark before a non-literal: %s", layout[:len(layout)-len(suffix)])
+		}
+		n.field = required
+		return n, suffix, nil
+	default:
+		// each literal character is optional on its own, like in the reference syntax
+		return node{text: layout[:1], field: fixed}, layout[1:], nil
+	}
+}
+
+// compilePlaceholder compiles a placeholder in the form of `{-name-:option}` without braces,
+// into a token, or a group of the token and the marks around it.
+func compilePlaceholder(placeholder string) (node, error) {
+	spec := placeholder
+	option := ""
+	if i := strings.IndexByte(spec, ':'); i != -1 {
+		spec, option = spec[:i], spec[i+1:]
+	}
+	start := 0
+	for start < len(spec) && isPlaceholderMark(spec[start]) {
+		start++
+	}
+	end := len(spec)
+	for end > start && isPlaceholderMark(spec[end-1]) {
+		end--
+	}
+	prefix, name, suffix := spec[:start], spec[start:end], spec[end:]
+
+	token, err := placeholderToken(name, option)
+	if err != nil {
+		return node{}, fmt.Errorf("%v: {%s}", err, placeholder)
+	}
+	if name == "pre" && (prefix == "" || prefix == "-") && (suffix == "" || suffix == "-") {
+		// dashes around a tag are written with the tag
+		token = prefix + token + suffix
+		prefix, suffix = "", ""
+	}
+	text, field, rest, err := nextChunk(token)
+	if err != nil || len(rest) > 0 {
+		panic("unexpected token from placeholder")
+	}
+	n := node{text: text, field: field}
+	if prefix == "" && suffix == "" {
+		return n, nil
+	}
+	alt := make([]node, 0, 3)
+	if prefix != "" {
+		alt = append(alt, node{text: prefix, field: fixed})
+	}
+	alt = append(alt, n)
+	if suffix != "" {
+		alt = append(alt, node{text: suffix, field: fixed})
+	}
+	return node{text: "[" + formatLiteral(prefix) + token + formatLiteral(suffix) + "]", field: group, alts: [][]node{alt}}, nil
+}
+
+// placeholderToken returns the token in the reference syntax for a placeholder name and its option.
+func placeholderToken(name string, option string) (string, error) {
+	switch name {
+	case "major", "minor", "patch", "build":
+		digit := map[string]string{"major": "5", "minor": "4", "patch": "3", "build": "1"}[name]
+		switch {
+		case option == "":
+			return digit, nil
+		case option == "alpha" && name == "patch":
+			return "y", nil
+		case option == "ALPHA" && name == "patch":
+			return "Y", nil
+		case option == "alpha" && name == "build":
+			return "z", nil
+		case option == "ALPHA" && name == "build":
+			return "Z", nil
+		}
+		width, err := placeholderWidth(option)
+		if err != nil {
+			return "", err
+		}
+		return strings.Repeat("0", width-1) + digit, nil
+	case "pre":
+		switch option {
+		case "":
+			return "beta", nil
+		case "b", "B", "beta", "Beta":
+			return option, nil
+		}
+	case "other":
+		if option == "" {
+			return "o", nil
+		}
+	case "year":
+		switch option {
+		case "", "YYYY":
+			return "YYYY", nil
+		case "YY", "0Y":
+			return option, nil
+		}
+	case "month", "week", "day":
+		letter := strings.ToUpper(name[:1])
+		switch option {
+		case "":
+			return letter + letter, nil
+		case "02":
+			return "0" + letter, nil
+		}
+	default:
+		return "", errors.New("unknown placeholder")
+	}
+	return "", fmt.Errorf("unknown option %s", option)
+}
+
+// placeholderWidth returns the width of an option like `03`.
+func placeholderWidth(option string) (int, error) {
+	if len(option) != 2 || option[0] != '0' || option[1] < '1' || option[1] > '9' {
+		return 0, fmt.Errorf("unknown option %s", option)
+	}
+	return int(option[1] - '0'), nil
+}
+
+// formatLiteral returns a literal in the reference syntax, quoted unless it is a dot or a v.
+func formatLiteral(text string) string {
+	switch text {
+	case "", ".", "v", "V":
+		return text
+	}
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
+
+// formatReferenceLiteral returns a literal in the reference syntax before the text written next,
+// unquoted if it is one character read as itself there, e.g. `-` but not `-` before `b`.
+func formatReferenceLiteral(text string, next string) string {
+	if len(text) == 1 && !isStructural(text[0]) && strings.IndexByte("{}$!'", text[0]) == -1 {
+		chunk, field, suffix, err := nextChunk(text + next)
+		if err == nil && field == fixed && chunk == text && len(suffix) == len(next) {
+			return text
+		}
+	}
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
+
+// formatNamedLiteral returns a literal in the named syntax, quoted if it has more than one character or is special.
+func formatNamedLiteral(text string) string {
+	if len(text) == 1 && !isStructural(text[0]) && strings.IndexByte("{}$!'", text[0]) == -1 {
+		return text
+	}
+	return "'" + strings.Replace(text, "'", "''", -1) + "'"
+}
+
+// Reference returns the layout in the reference syntax, like `v5.4[.3]-beta[.1]`.
+func (l *Layout) Reference() string {
+	return formatLayout(l.nodes, func(n node, next string) string {
+		switch n.field {
+		case fixed:
+			return formatReferenceLiteral(n.text, next)
+		case required:
+			return "!" + formatReferenceLiteral(n.text, next)
+		default:
+			return n.text
+		}
+	})
+}
+
+// Named returns the layout in the named syntax, like `v{major}.{minor}[.{patch}]{-pre}[.{build}]`.
+//
+// The placeholders are:
+//
+//	{major}, {minor}, {patch}, {build}   numeric counters, or zero-padded with an option like {patch:03}
+//	{patch:alpha}, {build:alpha}         alphabetic counters, or in capitals with the option ALPHA
+//	{pre}                                a pre-release tag like beta, or like b, B or Beta with the option
+//	{other}                              the remaining text
+//	{year}, {month}, {week}, {day}       calendar fields, or like {year:YY}, {year:0Y} and {month:02}
+//
+// Marks like `-`, `.`, `_`, `+` and `~` before or after the name are written with the field and omitted with it,
+// e.g. `{.build}` for `[.{build}]`, except that dashes around a tag are like in `-beta-`.
+// Other characters out of placeholders are literals, each optional on its own when reading unless marked with `!`,
+// and so is a quoted literal as a whole.
+func (l *Layout) Named() string {
+	return formatLayout(l.nodes, func(n node, next string) string {
+		switch n.field {
+		case fixed:
+			return formatNamedLiteral(n.text)
+		case required:
+			return "!" + formatNamedLiteral(n.text)
+		case allowEnd:
+			return "$"
+		}
+		return "{" + namedPlaceholder(n) + "}"
+	})
+}
+
+func namedPlaceholder(n node) string {
+	switch n.field {
+	case build, patch, minor, major:
+		name := map[Field]string{build: "build", patch: "patch", minor: "minor", major: "major"}[n.field]
+		if len(n.text) > 1 {
+			return fmt.Sprintf("%s:0%d", name, len(n.text))
+		}
+		return name
+	case alphabetic_build, alphabetic_patch:
+		name := map[Field]string{alphabetic_build: "build", alphabetic_patch: "patch"}[n.field]
+		if n.text == strings.ToUpper(n.text) {
+			return name + ":ALPHA"
+		}
+		return name + ":alpha"
+	case preRelTag:
+		style := strings.Trim(n.text, "-")
+		prefix, suffix := "", ""
+		if n.text[0] == '-' {
+			prefix = "-"
+		}
+		if n.text[len(n.text)-1] == '-' {
+			suffix = "-"
+		}
+		if style == "beta" {
+			return prefix + "pre" + suffix
+		}
+		return prefix + "pre" + suffix + ":" + style
+	case other:
+		return "other"
+	case year:
+		return "year"
+	case short_year:
+		return "year:" + n.text
+	case month, week, day:
+		name := map[Field]string{month: "month", week: "week", day: "day"}[n.field]
+		if n.text[0] == '0' {
+			return name + ":02"
+		}
+		return name
+	default:
+		panic("unexpected field to name")
+	}
+}
+
+// formatLayout writes the nodes with a function writing a chunk before the text written next,
+// where a quoted literal before another one is put in a choice of its own,
+// since two quoted literals in a row would read as one with a quote between them.
+func formatLayout(nodes []node, chunk func(n node, next string) string) string {
+	text := ""
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		if n.alts == nil {
+			written := chunk(n, text)
+			if strings.HasSuffix(written, "'") && strings.HasPrefix(text, "'") {
+				written = "(" + written + ")"
+			}
+			text = written + text
+			continue
+		}
+		var b strings.Builder
+		opening, closing := "[", "]"
+		if n.field == choice {
+			opening, closing = "(", ")"
+		}
+		b.WriteString(opening)
+		for i, alt := range n.alts {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			b.WriteString(formatLayout(alt, chunk))
+		}
+		b.WriteString(closing)
+		text = b.String() + text
+	}
+	return text
+}
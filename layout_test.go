@@ -0,0 +1,190 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestCompile(t *testing.T) {
+	for _, layout := range []string{"5.4", "5.4[.3][-beta[.1]]", "5.4.3(-b|.b)1", "5.4|YYYY.0M", "'[v]'5"} {
+		l, err := version.Compile(layout)
+		if err != nil {
+			t.Errorf("layout not compiled: %s, %v", layout, err)
+			continue
+		}
+		if l.String() != layout {
+			t.Errorf("layout source expectation failed, expected: %s, actual: %s", layout, l.String())
+		}
+	}
+
+	for _, layout := range []string{"5.4[.3", "5.4.3]", "5.4(.3", "5.4)", "5.4![.3]"} {
+		if _, err := version.Compile(layout); err == nil {
+			t.Errorf("ill-formed layout compiled: %s", layout)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	l := version.MustCompile("v5.4[.3]")
+	v, err := l.Parse("v1.2.3")
+	if err != nil || !v.EQ(&version.Version{Major: 1, Minor: 2, Patch: 3}) {
+		t.Errorf("version expectation failed, actual: %+v, %v", v, err)
+	}
+	if s := l.Format(&version.Version{Major: 1, Minor: 2}); s != "v1.2" {
+		t.Errorf("version expectation failed, expected: %v, actual: %v", "v1.2", s)
+	}
+}
+
+func TestNamedLayout(t *testing.T) {
+	cases := []struct {
+		Named     string
+		Reference string
+	}{
+		{"v{major}.{minor}[.{patch}]{-pre}{.build}", "v5.4[.3]-beta[.1]"},
+		{"{major:02}.{minor:02}.{patch:03}", "05.04.003"},
+		{"{major}.{minor}{patch:alpha}", "5.4y"},
+		{"{major}.{minor}.{patch}{pre:b}{build:ALPHA}", "5.4.3bZ"},
+		{"{year}.{month:02}.{day:02}$.{build}", "YYYY.0M.0D$.1"},
+		{"{year:YY}.{week}", "YY.WW"},
+		{"release-{major}.{minor}", "release-5.4"},
+		{"ab{major}", "a'b'5"},
+		{"{major}_{minor}-{build}", "5_4-1"},
+		{"{major}-b{minor}", "5-'b'4"},
+		{"{major}'ab'5{minor}", "5('ab')'5'4"},
+		{"!'release-'{major}({-pre-:Beta}|{.pre:b}){build}[+{other}]", "!'release-'5(-Beta-|[.b])1[+o]"},
+	}
+
+	for _, c := range cases {
+		l, err := version.Compile(c.Named)
+		if err != nil {
+			t.Errorf("layout not compiled: %s, %v", c.Named, err)
+			continue
+		}
+		if l.Reference() != c.Reference {
+			t.Errorf("reference expectation failed, expected: %s, actual: %s", c.Reference, l.Reference())
+		}
+		ref, err := version.Compile(c.Reference)
+		if err != nil {
+			t.Errorf("reference layout not compiled: %s, %v", c.Reference, err)
+			continue
+		}
+		if ref.Reference() != c.Reference {
+			t.Errorf("reference expectation failed, expected: %s, actual: %s", c.Reference, ref.Reference())
+		}
+		named, err := version.Compile(ref.Named())
+		if err != nil {
+			t.Errorf("named layout not compiled: %s, %v", ref.Named(), err)
+			continue
+		}
+		if named.Reference() != c.Reference {
+			t.Errorf("reference expectation failed, expected: %s, actual: %s, from: %s", c.Reference, named.Reference(), ref.Named())
+		}
+	}
+
+	for _, layout := range []string{"{major", "{mayor}", "{major:alpha}", "{build:3}", "{pre:rc}", "!{major}"} {
+		if _, err := version.Compile(layout); err == nil {
+			t.Errorf("ill-formed layout compiled: %s", layout)
+		}
+	}
+}
+
+func TestNamedLayoutRoundTrip(t *testing.T) {
+	cases := []struct {
+		Named    string
+		Version  *version.Version
+		Expected string
+	}{
+		{"release-{major}.{minor}", &version.Version{Major: 1, Minor: 2}, "release-1.2"},
+		{"ab{major}", &version.Version{Major: 3}, "ab3"},
+		{"v{major}x_y[.{patch}]", &version.Version{Major: 1, Patch: 4}, "v1x_y.4"},
+	}
+
+	for _, c := range cases {
+		named, err := version.Compile(c.Named)
+		if err != nil {
+			t.Errorf("layout not compiled: %s, %v", c.Named, err)
+			continue
+		}
+		ref, err := version.Compile(named.Reference())
+		if err != nil {
+			t.Errorf("reference layout not compiled: %s, %v", named.Reference(), err)
+			continue
+		}
+		if s := ref.Format(c.Version); s != c.Expected {
+			t.Errorf("format expectation failed, expected: %s, actual: %s, from: %s", c.Expected, s, named.Reference())
+		}
+		v, err := ref.Parse(c.Expected)
+		if err != nil || !v.EQ(c.Version) {
+			t.Errorf("parse expectation failed, expected: %+v, actual: %+v, %v", c.Version, v, err)
+		}
+	}
+}
+
+func TestLayoutConversionParse(t *testing.T) {
+	cases := []struct {
+		Layout   string
+		Versions []string
+	}{
+		{"v.5.4", []string{"v.1.2", "v1.2", ".1.2", "1.2", "v.1", "v..1.2"}},
+		{"5-.4", []string{"1-.2", "1.2", "1-2", "12", "1"}},
+		{"release-{major}.{minor}", []string{"release-1.2", "1.2", "rel-1.2", "ease1.2", "release1-2"}},
+		{"{major}'ab'5{minor}", []string{"1ab52", "1ab2", "152", "1a52", "1.2"}},
+		{"{major}-b{minor}", []string{"1-b2", "1b2", "1-2", "12", "1-beta2"}},
+	}
+
+	for _, c := range cases {
+		l := version.MustCompile(c.Layout)
+		for _, converted := range []string{l.Reference(), l.Named()} {
+			l2, err := version.Compile(converted)
+			if err != nil {
+				t.Errorf("converted layout not compiled: %s, %v; layout: %s", converted, err, c.Layout)
+				continue
+			}
+			for _, s := range c.Versions {
+				v1, err1 := l.Parse(s)
+				v2, err2 := l2.Parse(s)
+				if (err1 != nil) != (err2 != nil) || err1 == nil && !v1.EQ(v2) {
+					t.Errorf("layouts disagree on %s: %s, %+v, %v; %s, %+v, %v", s, c.Layout, v1, err1, converted, v2, err2)
+				}
+			}
+		}
+	}
+}
+
+func TestNamedLayoutParse(t *testing.T) {
+	named := version.MustCompile("v{major}.{minor}[.{patch}]{-pre}{.build}")
+	ref := version.MustCompile("v5.4[.3]-beta[.1]")
+	for _, s := range []string{"v1.2", "1.2.3", "v1.2-rc.1", "1.2.3-beta", "1.2.3-alpha.4", "1.2-"} {
+		v1, err1 := named.Parse(s)
+		v2, err2 := ref.Parse(s)
+		if (err1 != nil) != (err2 != nil) || err1 == nil && !v1.EQ(v2) {
+			t.Errorf("layouts disagree on %s: %+v, %v; %+v, %v", s, v1, err1, v2, err2)
+		}
+		if err1 == nil && named.Format(v1) != ref.Format(v2) {
+			t.Errorf("layouts disagree on %+v: %s; %s", v1, named.Format(v1), ref.Format(v2))
+		}
+	}
+	s := named.Format(&version.Version{Major: 1, Minor: 2, PreRel: version.ReleaseCandidate, Build: 3})
+	if s != "v1.2-rc.3" {
+		t.Errorf("version expectation failed, expected: %v, actual: %v", "v1.2-rc.3", s)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package migration runs data migrations in the order of versions,
+// e.g. to bring a config file from the schema version stored in it to the current one.
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gsxab/go-version"
+)
+
+// Func is a function migrating data.
+type Func func(ctx context.Context) error
+
+// Step is a migration from a version to a greater version, and optionally back.
+type Step struct {
+	From *version.Version
+	To   *version.Version
+	Up   Func
+	Down Func // the inverse of Up, or nil if the step cannot be reverted
+}
+
+// Registry is a set of steps, which make a chain of versions when sorted.
+type Registry struct {
+	layout *version.Layout
+	steps  []Step // sorted by To
+}
+
+// New returns an empty registry, where versions are written in the layout in errors and plans.
+func New(layout *version.Layout) *Registry {
+	return &Registry{layout: layout}
+}
+
+// Register adds a step, and returns an error if the step is not upgrading, or another step migrates to the same version.
+func (r *Registry) Register(step Step) error {
+	if step.From == nil || step.To == nil {
+		return errors.New("step without From or To")
+	}
+	if step.Up == nil {
+		return fmt.Errorf("step to %s without Up", r.layout.Format(step.To))
+	}
+	if !step.From.LT(step.To) {
+		return fmt.Errorf("step from %s to %s not upgrading", r.layout.Format(step.From), r.layout.Format(step.To))
+	}
+	i := sort.Search(len(r.steps), func(i int) bool {
+		return !r.steps[i].To.LT(step.To)
+	})
+	if i < len(r.steps) && r.steps[i].To.EQ(step.To) {
+		return fmt.Errorf("duplicate steps to %s", r.layout.Format(step.To))
+	}
+	r.steps = append(r.steps, Step{})
+	copy(r.steps[i+1:], r.steps[i:])
+	r.steps[i] = step
+	return nil
+}
+
+// MustRegister adds a step like Register, but panics if it fails, to register steps on initialization.
+func (r *Registry) MustRegister(step Step) {
+	if err := r.Register(step); err != nil {
+		panic(err)
+	}
+}
+
+// GapError tells the steps do not make a chain, where no step migrates from a version to the next step.
+type GapError struct {
+	From string // the version written in the layout, reached by a step or stored
+	Next string // the version the next step migrates from
+}
+
+func (e *GapError) Error() string {
+	return fmt.Sprintf("migration gap between %s and %s", e.From, e.Next)
+}
+
+// Validate returns a GapError if a step does not migrate from the version the step before migrates to.
+func (r *Registry) Validate() error {
+	for i := 1; i < len(r.steps); i++ {
+		if !r.steps[i].From.EQ(r.steps[i-1].To) {
+			return &GapError{From: r.layout.Format(r.steps[i-1].To), Next: r.layout.Format(r.steps[i].From)}
+		}
+	}
+	return nil
+}
+
+// PlannedStep is a step in a plan, upgrading with Up or downgrading with Down.
+type PlannedStep struct {
+	Step
+	Downgrade bool
+}
+
+// Plan is the steps to migrate from a version to another one, in order.
+type Plan struct {
+	From   *version.Version
+	To     *version.Version
+	Steps  []PlannedStep
+	layout *version.Layout
+}
+
+// Plan returns the steps to migrate from a version to another one without running them, i.e. a dry run.
+// A version between two steps is taken as the one the step before migrates to, e.g. with steps `1.0 -> 1.1 -> 2.0`,
+// data stored at `1.0.5` is upgraded to `2.0` by both steps, since the steps run are those migrating to a version
+// greater than the stored one and not greater than the target, or those reverted in the reverse order.
+// A version after the last step is taken the same way, e.g. data stored at `1.0` is upgraded to `2.3` by both steps,
+// and data stored at `2.0` is upgraded to `2.5` by none.
+// It returns a GapError if no chain of steps connects the versions,
+// or an error if a step to revert has no Down.
+func (r *Registry) Plan(from *version.Version, to *version.Version) (*Plan, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	plan := &Plan{From: from, To: to, Steps: make([]PlannedStep, 0), layout: r.layout}
+	switch {
+	case from.EQ(to):
+		return plan, nil
+	case from.LT(to):
+		for _, s := range r.steps {
+			if from.LT(s.To) && !to.LT(s.To) {
+				plan.Steps = append(plan.Steps, PlannedStep{Step: s})
+			}
+		}
+		if err := r.checkStart(plan, from); err != nil {
+			return nil, err
+		}
+	default:
+		for i := len(r.steps) - 1; i >= 0; i-- {
+			s := r.steps[i]
+			if !to.LT(s.To) || from.LT(s.To) {
+				continue
+			}
+			if s.Down == nil {
+				return nil, fmt.Errorf("step from %s to %s not revertible", r.layout.Format(s.From), r.layout.Format(s.To))
+			}
+			plan.Steps = append(plan.Steps, PlannedStep{Step: s, Downgrade: true})
+		}
+		if err := r.checkStart(plan, to); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// checkStart returns a GapError if the steps planned do not start from the lower version of the plan.
+func (r *Registry) checkStart(plan *Plan, lower *version.Version) error {
+	if len(plan.Steps) == 0 {
+		return nil
+	}
+	first := plan.Steps[0]
+	if first.Downgrade {
+		first = plan.Steps[len(plan.Steps)-1]
+	}
+	if lower.LT(first.From) {
+		return &GapError{From: r.layout.Format(lower), Next: r.layout.Format(first.From)}
+	}
+	return nil
+}
+
+// reached returns the version after the step is run.
+func (s PlannedStep) reached() *version.Version {
+	if s.Downgrade {
+		return s.From
+	}
+	return s.To
+}
+
+// String lists the steps, e.g. `1.0 -> 1.1 -> 2.0`, or the version alone if there is no step.
+func (p *Plan) String() string {
+	versions := []string{p.layout.Format(p.From)}
+	for _, s := range p.Steps {
+		versions = append(versions, p.layout.Format(s.reached()))
+	}
+	return strings.Join(versions, " -> ")
+}
+
+// StepError tells a step failed, with the last version migrated to.
+type StepError struct {
+	From string // the version the failed step migrates from, i.e. the last version reached
+	To   string // the version the failed step migrates to
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("migration from %s to %s failed: %v", e.From, e.To, e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Run runs the steps in order, and returns the last version reached,
+// which is the target of the plan, or the version before the first step failed with a StepError.
+func (p *Plan) Run(ctx context.Context) (*version.Version, error) {
+	reached := p.From
+	for _, s := range p.Steps {
+		from, run := s.From, s.Up
+		if s.Downgrade {
+			from, run = s.To, s.Down
+		}
+		if err := ctx.Err(); err != nil {
+			return reached, err
+		}
+		if err := run(ctx); err != nil {
+			return reached, &StepError{From: p.layout.Format(from), To: p.layout.Format(s.reached()), Err: err}
+		}
+		reached = s.reached()
+	}
+	return p.To, nil
+}
+
+// Run plans and runs the steps to migrate from a version to another one,
+// and returns the last version reached, like Plan.Run.
+func (r *Registry) Run(ctx context.Context, from *version.Version, to *version.Version) (*version.Version, error) {
+	plan, err := r.Plan(from, to)
+	if err != nil {
+		return from, err
+	}
+	return plan.Run(ctx)
+}
@@ -0,0 +1,186 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gsxab/go-version"
+	"github.com/gsxab/go-version/migration"
+)
+
+var layout = version.MustCompile("5.4")
+
+func mustParse(t *testing.T, versionString string) *version.Version {
+	v, err := layout.Parse(versionString)
+	if err != nil {
+		t.Fatalf("unexpected error: %v; version: %v", err, versionString)
+	}
+	return v
+}
+
+// registry returns a registry of steps from each version to the next, which log to the builder.
+func registry(t *testing.T, log *strings.Builder, versions ...string) *migration.Registry {
+	r := migration.New(layout)
+	for i := len(versions) - 1; i > 0; i-- {
+		from, to := versions[i-1], versions[i]
+		step := migration.Step{
+			From: mustParse(t, from),
+			To:   mustParse(t, to),
+			Up: func(ctx context.Context) error {
+				log.WriteString("up " + to + ";")
+				return nil
+			},
+			Down: func(ctx context.Context) error {
+				log.WriteString("down " + from + ";")
+				return nil
+			},
+		}
+		if err := r.Register(step); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return r
+}
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		From     string
+		To       string
+		Plan     string
+		Expected string
+	}{
+		{"1.0", "2.0", "1.0 -> 1.1 -> 1.2 -> 2.0", "up 1.1;up 1.2;up 2.0;"},
+		{"1.1", "1.2", "1.1 -> 1.2", "up 1.2;"},
+		{"2.0", "1.1", "2.0 -> 1.2 -> 1.1", "down 1.2;down 1.1;"},
+		{"1.2", "1.2", "1.2", ""},
+		{"1.5", "2.0", "1.5 -> 2.0", "up 2.0;"},
+		{"1.0", "1.5", "1.0 -> 1.1 -> 1.2", "up 1.1;up 1.2;"},
+		{"1.1", "1.1", "1.1", ""},
+		{"2.0", "1.5", "2.0 -> 1.2", "down 1.2;"},
+		{"1.5", "1.1", "1.5 -> 1.1", "down 1.1;"},
+		{"1.0", "2.3", "1.0 -> 1.1 -> 1.2 -> 2.0", "up 1.1;up 1.2;up 2.0;"},
+		{"2.0", "2.5", "2.0", ""},
+		{"1.5", "2.5", "1.5 -> 2.0", "up 2.0;"},
+		{"2.5", "1.2", "2.5 -> 1.2", "down 1.2;"},
+	}
+
+	for _, c := range cases {
+		var log strings.Builder
+		r := registry(t, &log, "1.0", "1.1", "1.2", "2.0")
+		plan, err := r.Plan(mustParse(t, c.From), mustParse(t, c.To))
+		if err != nil {
+			t.Errorf("unexpected error: %v; from: %v, to: %v", err, c.From, c.To)
+			continue
+		}
+		if plan.String() != c.Plan {
+			t.Errorf("plan expectation failed, expected: %v, actual: %v", c.Plan, plan)
+		}
+		if log.Len() != 0 {
+			t.Errorf("steps run in planning: %v", log.String())
+		}
+		reached, err := r.Run(context.Background(), mustParse(t, c.From), mustParse(t, c.To))
+		if err != nil || !reached.EQ(mustParse(t, c.To)) {
+			t.Errorf("run expectation failed, expected: %v, actual: %+v, %v", c.To, reached, err)
+		}
+		if log.String() != c.Expected {
+			t.Errorf("steps expectation failed, expected: %v, actual: %v", c.Expected, log.String())
+		}
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	var log strings.Builder
+	r := registry(t, &log, "1.0", "1.1")
+	errBroken := errors.New("broken")
+	r.MustRegister(migration.Step{
+		From: mustParse(t, "1.1"),
+		To:   mustParse(t, "1.2"),
+		Up: func(ctx context.Context) error {
+			return errBroken
+		},
+	})
+	r.MustRegister(migration.Step{
+		From: mustParse(t, "1.2"),
+		To:   mustParse(t, "1.3"),
+		Up: func(ctx context.Context) error {
+			log.WriteString("up 1.3;")
+			return nil
+		},
+	})
+
+	reached, err := r.Run(context.Background(), mustParse(t, "1.0"), mustParse(t, "1.3"))
+	var stepErr *migration.StepError
+	if !errors.As(err, &stepErr) || !errors.Is(err, errBroken) || stepErr.From != "1.1" || stepErr.To != "1.2" {
+		t.Errorf("error expectation failed, expected: %T, actual: %v", stepErr, err)
+	}
+	if !reached.EQ(mustParse(t, "1.1")) || log.String() != "up 1.1;" {
+		t.Errorf("run expectation failed, expected: 1.1, actual: %+v, %v", reached, log.String())
+	}
+
+	if _, err := r.Plan(mustParse(t, "1.3"), mustParse(t, "1.0")); err == nil {
+		t.Errorf("downgrade without Down accepted")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if reached, err := r.Run(ctx, mustParse(t, "1.0"), mustParse(t, "1.1")); !errors.Is(err, context.Canceled) || !reached.EQ(mustParse(t, "1.0")) {
+		t.Errorf("cancellation expectation failed, actual: %+v, %v", reached, err)
+	}
+}
+
+func TestRegistryError(t *testing.T) {
+	var log strings.Builder
+	r := registry(t, &log, "1.0", "1.1", "1.2")
+	noop := func(ctx context.Context) error { return nil }
+
+	if err := r.Register(migration.Step{From: mustParse(t, "1.0"), To: mustParse(t, "1.2"), Up: noop}); err == nil {
+		t.Errorf("duplicate target accepted")
+	}
+	if err := r.Register(migration.Step{From: mustParse(t, "1.5"), To: mustParse(t, "1.4"), Up: noop}); err == nil {
+		t.Errorf("downgrading step accepted")
+	}
+	if err := r.Register(migration.Step{From: mustParse(t, "1.5"), To: mustParse(t, "1.6")}); err == nil {
+		t.Errorf("step without Up accepted")
+	}
+	if err := r.Register(migration.Step{To: mustParse(t, "1.6"), Up: noop}); err == nil {
+		t.Errorf("step without From accepted")
+	}
+	if err := r.Register(migration.Step{From: mustParse(t, "1.5"), Up: noop}); err == nil {
+		t.Errorf("step without To accepted")
+	}
+
+	var gapErr *migration.GapError
+	if _, err := r.Plan(mustParse(t, "0.9"), mustParse(t, "1.2")); !errors.As(err, &gapErr) || gapErr.From != "0.9" || gapErr.Next != "1.0" {
+		t.Errorf("error expectation failed, expected: %T, actual: %v", gapErr, err)
+	}
+	if _, err := r.Plan(mustParse(t, "1.2"), mustParse(t, "0.9")); !errors.As(err, &gapErr) || gapErr.From != "0.9" || gapErr.Next != "1.0" {
+		t.Errorf("error expectation failed, expected: %T, actual: %v", gapErr, err)
+	}
+
+	r.MustRegister(migration.Step{From: mustParse(t, "1.5"), To: mustParse(t, "1.6"), Up: noop})
+	if err := r.Validate(); !errors.As(err, &gapErr) || gapErr.From != "1.2" || gapErr.Next != "1.5" {
+		t.Errorf("error expectation failed, expected: %T, actual: %v", gapErr, err)
+	}
+	if _, err := r.Plan(mustParse(t, "1.0"), mustParse(t, "1.1")); !errors.As(err, &gapErr) {
+		t.Errorf("error expectation failed, expected: %T, actual: %v", gapErr, err)
+	}
+}
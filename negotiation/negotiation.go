@@ -0,0 +1,121 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package negotiation agrees on a protocol version between two peers,
+// which advertise the versions they support to each other in a handshake.
+package negotiation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gsxab/go-version"
+)
+
+// less reports whether a version is less than another one, where versions equal except the other text
+// are ordered by the other text, so that both peers choose the same one.
+func less(v *version.Version, v2 *version.Version) bool {
+	return v.LT(v2) || v.EQ(v2) && v.Other < v2.Other
+}
+
+func same(v *version.Version, v2 *version.Version) bool {
+	return v.EQ(v2) && v.Other == v2.Other
+}
+
+// Error tells why two peers do not agree on a version.
+type Error struct {
+	Local  []string // the versions supported locally, written in the layout
+	Remote []string // the versions supported by the peer, written in the layout
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("no common version, local versions: %s, remote versions: %s: %s",
+		listString(e.Local), listString(e.Remote), e.Reason)
+}
+
+func listString(versions []string) string {
+	if len(versions) == 0 {
+		return "none"
+	}
+	return strings.Join(versions, ", ")
+}
+
+// Negotiate returns the greatest version supported by both peers, or an Error if there is none.
+// Versions are the same if they are equal including the other text, which orders versions otherwise equal,
+// so the result does not depend on which side is local. The versions are written in the layout in errors.
+func Negotiate(layout *version.Layout, local []*version.Version, remote []*version.Version) (*version.Version, error) {
+	var best *version.Version
+	for _, v := range local {
+		if best != nil && !less(best, v) {
+			continue
+		}
+		for _, v2 := range remote {
+			if same(v, v2) {
+				best = v
+				break
+			}
+		}
+	}
+	if best != nil {
+		return best, nil
+	}
+
+	e := &Error{Local: formatAll(layout, local), Remote: formatAll(layout, remote)}
+	switch {
+	case len(local) == 0:
+		e.Reason = "no version supported locally"
+	case len(remote) == 0:
+		e.Reason = "no version supported by the peer"
+	case greatest(remote).LT(least(local)):
+		e.Reason = "peer too old"
+	case greatest(local).LT(least(remote)):
+		e.Reason = "peer too new"
+	default:
+		e.Reason = "versions supported do not overlap"
+	}
+	return nil, e
+}
+
+func formatAll(layout *version.Layout, versions []*version.Version) []string {
+	strs := make([]string, len(versions))
+	for i, v := range versions {
+		strs[i] = layout.Format(v)
+	}
+	return strs
+}
+
+func greatest(versions []*version.Version) *version.Version {
+	g := versions[0]
+	for _, v := range versions[1:] {
+		if less(g, v) {
+			g = v
+		}
+	}
+	return g
+}
+
+func least(versions []*version.Version) *version.Version {
+	l := versions[0]
+	for _, v := range versions[1:] {
+		if less(v, l) {
+			l = v
+		}
+	}
+	return l
+}
@@ -0,0 +1,263 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package negotiation_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gsxab/go-version"
+	"github.com/gsxab/go-version/negotiation"
+)
+
+var layout = version.MustCompile("5.4[.3]['+'o]")
+
+func mustParseAll(t *testing.T, versionStrings string) []*version.Version {
+	versions := make([]*version.Version, 0)
+	for _, s := range strings.Fields(versionStrings) {
+		v, err := layout.Parse(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %v; version: %v", err, s)
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		Local    string
+		Remote   string
+		Expected string
+		Reason   string
+	}{
+		{"1.0 1.1 1.2", "1.1 1.2 2.0", "1.2", ""},
+		{"1.2 1.0 1.1", "2.0 1.1", "1.1", ""},
+		{"1.0 1.1+x 1.1+y", "1.1+y 1.1+x", "1.1+y", ""},
+		{"1.0 1.1+x", "1.1+y", "", "versions supported do not overlap"},
+		{"2.0 2.1", "1.0 1.1", "", "peer too old"},
+		{"1.0 1.1", "2.0 2.1", "", "peer too new"},
+		{"1.0 2.0", "1.1 2.1", "", "versions supported do not overlap"},
+		{"", "1.0", "", "no version supported locally"},
+		{"1.0", "", "", "no version supported by the peer"},
+	}
+
+	for _, c := range cases {
+		for _, swapped := range []bool{false, true} {
+			local, remote := mustParseAll(t, c.Local), mustParseAll(t, c.Remote)
+			if swapped {
+				if c.Reason != "" {
+					continue
+				}
+				local, remote = remote, local
+			}
+			v, err := negotiation.Negotiate(layout, local, remote)
+			if c.Reason != "" {
+				var e *negotiation.Error
+				if !errors.As(err, &e) || e.Reason != c.Reason {
+					t.Errorf("error expectation failed, expected: %v, actual: %v; local: %v, remote: %v", c.Reason, err, c.Local, c.Remote)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v; local: %v, remote: %v", err, c.Local, c.Remote)
+				continue
+			}
+			if actual := layout.Format(v); actual != c.Expected {
+				t.Errorf("negotiation expectation failed, expected: %v, actual: %v; local: %v, remote: %v", c.Expected, actual, c.Local, c.Remote)
+			}
+		}
+	}
+}
+
+func TestError(t *testing.T) {
+	_, err := negotiation.Negotiate(layout, mustParseAll(t, "2.0 2.1"), mustParseAll(t, "1.0"))
+	expected := "no common version, local versions: 2.0, 2.1, remote versions: 1.0: peer too old"
+	if err == nil || err.Error() != expected {
+		t.Errorf("error expectation failed, expected: %v, actual: %v", expected, err)
+	}
+}
+
+func TestReadVersions(t *testing.T) {
+	var buf bytes.Buffer
+	versions := mustParseAll(t, "1.0 1.2.3 2.0+build")
+	if err := negotiation.WriteVersions(&buf, layout, versions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	encoded := buf.Bytes()
+
+	cases := []struct {
+		Data     []byte
+		Expected string
+		Err      string
+	}{
+		{encoded, "1.0 1.2.3 2.0+build", ""},
+		{append(append([]byte{}, encoded...), "rest"...), "1.0 1.2.3 2.0+build", ""},
+		{[]byte{1, 0}, "", ""},
+		{encoded[:len(encoded)-1], "", "unexpected EOF"},
+		{encoded[:1], "", "unexpected EOF"},
+		{[]byte{}, "", "EOF"},
+		{[]byte{2, 0}, "", "unknown advertisement format 2"},
+		{[]byte{1, 0xff, 0xff, 0x01}, "", "too many versions advertised: 32767"},
+		{[]byte{1, 1, 0x80, 0x02}, "", "version advertised too long: 256 bytes"},
+		{[]byte{1, 1, 3, 'x', '.', '1'}, "", "version advertised not readable"},
+	}
+
+	for _, c := range cases {
+		versions, err := negotiation.ReadVersions(bytes.NewReader(c.Data), layout)
+		if c.Err != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), c.Err) {
+				t.Errorf("error expectation failed, expected: %v, actual: %v; data: %v", c.Err, err, c.Data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v; data: %v", err, c.Data)
+			continue
+		}
+		actual := make([]string, len(versions))
+		for i, v := range versions {
+			actual[i] = layout.Format(v)
+		}
+		if strings.Join(actual, " ") != c.Expected {
+			t.Errorf("versions expectation failed, expected: %v, actual: %v; data: %v", c.Expected, actual, c.Data)
+		}
+	}
+}
+
+func TestWriteVersionsNotRepresentable(t *testing.T) {
+	var buf bytes.Buffer
+	versions := []*version.Version{{Major: 1}, {Major: 1, Build: 2}}
+	var formatErr *version.FormatError
+	if err := negotiation.WriteVersions(&buf, layout, versions); !errors.As(err, &formatErr) {
+		t.Errorf("error expectation failed, expected: %T, actual: %v", formatErr, err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("nothing expected to be written, actual: %v", buf.Bytes())
+	}
+}
+
+func TestReadVersionsNotReadingAfter(t *testing.T) {
+	var buf bytes.Buffer
+	if err := negotiation.WriteVersions(&buf, layout, mustParseAll(t, "1.0")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buf.WriteString("hello")
+	if _, err := negotiation.ReadVersions(&buf, layout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rest := buf.String(); rest != "hello" {
+		t.Errorf("rest expectation failed, expected: %v, actual: %v", "hello", rest)
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	cases := []struct {
+		Client   string
+		Server   string
+		Expected string
+	}{
+		{"1.0 1.1 1.2", "1.1 1.2 2.0", "1.2"},
+		{"1.0 1.1+x 1.1+y", "1.1+y 1.1+x", "1.1+y"},
+		{"2.0", "1.0 1.1", ""},
+		{"", "1.0", ""},
+	}
+
+	for _, c := range cases {
+		clientVersions, serverVersions := mustParseAll(t, c.Client), mustParseAll(t, c.Server)
+		client, server := net.Pipe()
+		type result struct {
+			v   *version.Version
+			err error
+		}
+		results := make(chan result, 1)
+		go func() {
+			defer server.Close()
+			v, err := negotiation.Handshake(server, layout, serverVersions)
+			if err == nil {
+				_, err = server.Write([]byte("ok"))
+			}
+			results <- result{v, err}
+		}()
+
+		v, err := negotiation.Handshake(client, layout, clientVersions)
+		if err == nil {
+			ok := make([]byte, 2)
+			if _, err := io.ReadFull(client, ok); err != nil || string(ok) != "ok" {
+				t.Errorf("unexpected error after handshake: %v; client: %v, server: %v", err, c.Client, c.Server)
+			}
+		}
+		client.Close()
+		serverResult := <-results
+
+		for _, r := range []result{{v, err}, serverResult} {
+			if c.Expected == "" {
+				var e *negotiation.Error
+				if !errors.As(r.err, &e) {
+					t.Errorf("error expectation failed, expected: %T, actual: %v; client: %v, server: %v", e, r.err, c.Client, c.Server)
+				}
+				continue
+			}
+			if r.err != nil {
+				t.Errorf("unexpected error: %v; client: %v, server: %v", r.err, c.Client, c.Server)
+				continue
+			}
+			if actual := layout.Format(r.v); actual != c.Expected {
+				t.Errorf("handshake expectation failed, expected: %v, actual: %v; client: %v, server: %v", c.Expected, actual, c.Client, c.Server)
+			}
+		}
+	}
+}
+
+func TestHandshakeClosed(t *testing.T) {
+	client, server := net.Pipe()
+	server.Close()
+	if _, err := negotiation.Handshake(client, layout, mustParseAll(t, "1.0")); err == nil {
+		t.Errorf("error expected on a closed connection")
+	}
+}
+
+func TestHandshakeReadFailure(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	go server.Write([]byte{0xff}) // an unknown format, without reading the advertisement of the client
+
+	returned := make(chan error, 1)
+	go func() {
+		_, err := negotiation.Handshake(client, layout, mustParseAll(t, "1.0 1.1"))
+		returned <- err
+	}()
+	select {
+	case err := <-returned:
+		if err == nil {
+			t.Errorf("error expected on an unknown format")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("handshake not returned after a failed read")
+	}
+	// the connection is closed, instead of left to the advertisement still being written
+	if n, err := server.Read(make([]byte, 16)); err != io.EOF {
+		t.Errorf("closed connection expected, actual: %d bytes read, %v", n, err)
+	}
+}
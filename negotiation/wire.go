@@ -0,0 +1,149 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package negotiation
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gsxab/go-version"
+)
+
+// wireFormat is the first byte of an advertisement, to tell its format.
+const wireFormat = 1
+
+// Limits of an advertisement, so that a broken or hostile peer does not make the reader allocate too much.
+const (
+	MaxVersions      = 1024
+	MaxVersionLength = 255
+)
+
+// WriteVersions advertises the versions supported, as a format byte, a uvarint count, and each version written in the layout,
+// preceded by its length as a uvarint.
+// It returns the error of FormatStrict if a version is not representable in the layout, rather than advertise another one.
+func WriteVersions(w io.Writer, layout *version.Layout, versions []*version.Version) error {
+	if len(versions) > MaxVersions {
+		return fmt.Errorf("too many versions to advertise: %d", len(versions))
+	}
+	buf := []byte{wireFormat}
+	buf = appendUvarint(buf, uint64(len(versions)))
+	for _, v := range versions {
+		s, err := layout.FormatStrict(v)
+		if err != nil {
+			return err
+		}
+		if len(s) > MaxVersionLength {
+			return fmt.Errorf("version %s too long to advertise", s)
+		}
+		buf = appendUvarint(buf, uint64(len(s)))
+		buf = append(buf, s...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], x)
+	return append(buf, tmp[:n]...)
+}
+
+// ReadVersions reads the versions advertised by WriteVersions, in the layout.
+// It reads no byte after the advertisement, so the connection can be used for the protocol afterwards.
+func ReadVersions(r io.Reader, layout *version.Layout) ([]*version.Version, error) {
+	br := byteReader{r}
+	format, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if format != wireFormat {
+		return nil, fmt.Errorf("unknown advertisement format %d", format)
+	}
+	count, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if count > MaxVersions {
+		return nil, fmt.Errorf("too many versions advertised: %d", count)
+	}
+	versions := make([]*version.Version, count)
+	for i := range versions {
+		length, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if length > MaxVersionLength {
+			return nil, fmt.Errorf("version advertised too long: %d bytes", length)
+		}
+		s := make([]byte, length)
+		if _, err := io.ReadFull(r, s); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if versions[i], err = layout.Parse(string(s)); err != nil {
+			return nil, fmt.Errorf("version advertised not readable: %w", err)
+		}
+	}
+	return versions, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// byteReader reads a byte at a time, without buffering bytes after those read.
+type byteReader struct {
+	r io.Reader
+}
+
+func (br byteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(br.r, b[:])
+	return b[0], err
+}
+
+// Handshake advertises the versions supported locally and reads those of the peer at the same time,
+// and returns the version negotiated, like Negotiate.
+// Both peers come to the same version, or both fail with an Error, without another round trip.
+//
+// If the advertisement of the peer is not read, the connection is closed if it is an io.Closer,
+// so that the advertisement being written to a peer not reading it stops, and Handshake returns after it stops.
+// Otherwise, the caller must close the connection to stop it.
+func Handshake(conn io.ReadWriter, layout *version.Layout, local []*version.Version) (*version.Version, error) {
+	written := make(chan error, 1)
+	go func() {
+		written <- WriteVersions(conn, layout, local)
+	}()
+	remote, err := ReadVersions(conn, layout)
+	if err != nil {
+		if closer, ok := conn.(io.Closer); ok {
+			closer.Close()
+			<-written
+		}
+		return nil, err
+	}
+	if err := <-written; err != nil {
+		return nil, err
+	}
+	return Negotiate(layout, local, remote)
+}
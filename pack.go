@@ -0,0 +1,186 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+)
+
+// PackedField is a field packed into an integer, and its width in bits, or in decimal digits.
+type PackedField struct {
+	Field Field
+	Width int
+}
+
+// Packing describes how a version is packed into a fixed-width integer,
+// e.g. `{Fields: []PackedField{{MajorField, 16}, {MinorField, 16}, {PatchField, 16}, {BuildField, 16}}}`
+// for a FILEVERSION of Windows, or `{Fields: []PackedField{{MajorField, 3}, {MinorField, 3}, {PatchField, 3}}, Decimal: true}`
+// for a version code like `1002003` of 1.2.3.
+//
+// The fields are listed from the most significant, in the order of comparison, i.e. major, minor, patch, pre-release tag and build,
+// so that the packed integers are in the same order as the versions.
+// A field may be left out, and then only the versions with the field zero are packed.
+// The pre-release tag is packed as 0 for alpha, 1 for beta, 2 for rc and 3 for release, which takes 2 bits or 1 digit.
+type Packing struct {
+	Fields  []PackedField
+	Decimal bool // whether the widths are in decimal digits instead of bits
+}
+
+// OverflowError tells a field of a version is out of the range of its width in a Packing.
+type OverflowError struct {
+	Field   Field
+	Value   int64
+	Width   int // the width in the packing, or zero if the field is left out
+	Decimal bool
+}
+
+func (e *OverflowError) Error() string {
+	if e.Width == 0 {
+		return fmt.Sprintf("%v %d not packed, it must be zero", e.Field, e.Value)
+	}
+	unit := "bits"
+	if e.Decimal {
+		unit = "digits"
+	}
+	return fmt.Sprintf("%v %d out of range of %d %s", e.Field, e.Value, e.Width, unit)
+}
+
+// Pack32 packs a version into a uint32,
+// and returns an OverflowError if a field is negative or too large for its width.
+func (p *Packing) Pack32(v *Version) (uint32, error) {
+	packed, err := p.pack(v, 32)
+	return uint32(packed), err
+}
+
+// Pack64 packs a version into a uint64,
+// and returns an OverflowError if a field is negative or too large for its width.
+func (p *Packing) Pack64(v *Version) (uint64, error) {
+	return p.pack(v, 64)
+}
+
+// Unpack32 unpacks a version packed by Pack32.
+func (p *Packing) Unpack32(packed uint32) (*Version, error) {
+	return p.unpack(uint64(packed), 32)
+}
+
+// Unpack64 unpacks a version packed by Pack64.
+func (p *Packing) Unpack64(packed uint64) (*Version, error) {
+	return p.unpack(packed, 64)
+}
+
+// check returns an error if the packing is ill-formed, or too wide for the integer of bits.
+func (p *Packing) check(bits int) error {
+	if len(p.Fields) == 0 {
+		return fmt.Errorf("no field in packing")
+	}
+	capacity := 1.0
+	var last Field
+	for _, f := range p.Fields {
+		switch f.Field {
+		case major, minor, patch, preRelTag, build:
+		default:
+			return fmt.Errorf("%v not packable", f.Field)
+		}
+		if last != 0 && f.Field >= last {
+			return fmt.Errorf("%v packed after %v, not in the order of comparison", f.Field, last)
+		}
+		last = f.Field
+		if f.Width < 1 || !p.Decimal && f.Width > 63 || p.Decimal && f.Width > 18 {
+			return fmt.Errorf("%v of width %d not packable", f.Field, f.Width)
+		}
+		capacity *= float64(p.capacity(f.Width))
+	}
+	if capacity > float64(uint64(1)<<(bits-1))*2 {
+		return fmt.Errorf("packing too wide for %d bits", bits)
+	}
+	return nil
+}
+
+// capacity returns the number of values of the width.
+func (p *Packing) capacity(width int) uint64 {
+	if !p.Decimal {
+		return uint64(1) << width
+	}
+	capacity := uint64(1)
+	for i := 0; i < width; i++ {
+		capacity *= 10
+	}
+	return capacity
+}
+
+func (p *Packing) width(field Field) int {
+	for _, f := range p.Fields {
+		if f.Field == field {
+			return f.Width
+		}
+	}
+	return 0
+}
+
+func packedValue(field Field, v *Version) int64 {
+	if field == preRelTag {
+		return int64(v.PreRel - Alpha)
+	}
+	return field.value(v)
+}
+
+func (p *Packing) pack(v *Version, bits int) (uint64, error) {
+	if err := p.check(bits); err != nil {
+		return 0, err
+	}
+	for _, field := range sortKeyFields {
+		if p.width(field) == 0 && field.value(v) != 0 {
+			return 0, &OverflowError{Field: field, Value: field.value(v), Decimal: p.Decimal}
+		}
+	}
+	var packed uint64
+	for _, f := range p.Fields {
+		val, capacity := packedValue(f.Field, v), p.capacity(f.Width)
+		if val < 0 || uint64(val) >= capacity || f.Field == preRelTag && v.PreRel > Release {
+			return 0, &OverflowError{Field: f.Field, Value: f.Field.value(v), Width: f.Width, Decimal: p.Decimal}
+		}
+		packed = packed*capacity + uint64(val)
+	}
+	return packed, nil
+}
+
+func (p *Packing) unpack(packed uint64, bits int) (*Version, error) {
+	if err := p.check(bits); err != nil {
+		return nil, err
+	}
+	v := &Version{}
+	rest := packed
+	for i := len(p.Fields) - 1; i >= 0; i-- {
+		f := p.Fields[i]
+		capacity := p.capacity(f.Width)
+		val := int64(rest % capacity)
+		rest /= capacity
+		if f.Field == preRelTag {
+			if val > int64(Release-Alpha) {
+				return nil, fmt.Errorf("packed version %d with unknown tag %d", packed, val)
+			}
+			val += int64(Alpha)
+		}
+		f.Field.SetField(v, val)
+	}
+	if rest != 0 {
+		return nil, fmt.Errorf("packed version %d out of range of the packing", packed)
+	}
+	return v, nil
+}
@@ -0,0 +1,132 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestPack(t *testing.T) {
+	fileVersion := &version.Packing{Fields: []version.PackedField{
+		{version.MajorField, 16}, {version.MinorField, 16}, {version.PatchField, 16}, {version.BuildField, 16},
+	}}
+	versionCode := &version.Packing{Fields: []version.PackedField{
+		{version.MajorField, 2}, {version.MinorField, 2}, {version.PatchField, 2}, {version.PreRelField, 1}, {version.BuildField, 2},
+	}, Decimal: true}
+
+	cases := []struct {
+		Packing  *version.Packing
+		Version  *version.Version
+		Expected uint64
+		Overflow version.Field
+	}{
+		{fileVersion, &version.Version{Major: 1, Minor: 2, Patch: 3, Build: 4}, 0x0001000200030004, 0},
+		{fileVersion, &version.Version{Major: 65535, Minor: 65535, Patch: 65535, Build: 65535}, 0xffffffffffffffff, 0},
+		{fileVersion, &version.Version{Major: 65536}, 0, version.MajorField},
+		{fileVersion, &version.Version{Major: 1, Minor: -1}, 0, version.MinorField},
+		{fileVersion, &version.Version{Major: 1, PreRel: version.Beta}, 0, version.PreRelField},
+		{versionCode, &version.Version{Major: 1, Minor: 2, Patch: 3}, 10203300, 0},
+		{versionCode, &version.Version{Major: 1, Minor: 2, Patch: 3, PreRel: version.ReleaseCandidate, Build: 4}, 10203204, 0},
+		{versionCode, &version.Version{Major: 99, Minor: 2, Patch: 100}, 0, version.PatchField},
+		{versionCode, &version.Version{PreRel: 7}, 0, version.PreRelField},
+	}
+
+	for _, c := range cases {
+		packed, err := c.Packing.Pack64(c.Version)
+		if c.Overflow != 0 {
+			var overflowErr *version.OverflowError
+			if !errors.As(err, &overflowErr) || overflowErr.Field != c.Overflow {
+				t.Errorf("error expectation failed, expected overflow of %v, actual: %v; version: %+v", c.Overflow, err, c.Version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v; version: %+v", err, c.Version)
+			continue
+		}
+		if packed != c.Expected {
+			t.Errorf("packed expectation failed, expected: %v, actual: %v; version: %+v", c.Expected, packed, c.Version)
+		}
+		v, err := c.Packing.Unpack64(packed)
+		if err != nil || !v.EQ(c.Version) {
+			t.Errorf("unpacked expectation failed, expected: %+v, actual: %+v, %v", c.Version, v, err)
+		}
+	}
+}
+
+func TestPack32(t *testing.T) {
+	p := &version.Packing{Fields: []version.PackedField{
+		{version.MajorField, 8}, {version.MinorField, 8}, {version.PatchField, 8}, {version.PreRelField, 2}, {version.BuildField, 6},
+	}}
+	versions := []*version.Version{
+		{Major: 1, Minor: 9, PreRel: version.Alpha, Build: 1},
+		{Major: 1, Minor: 9, PreRel: version.Beta},
+		{Major: 1, Minor: 9, PreRel: version.ReleaseCandidate, Build: 63},
+		{Major: 1, Minor: 9},
+		{Major: 1, Minor: 10},
+		{Major: 2},
+	}
+	var last uint32
+	for i, v := range versions {
+		packed, err := p.Pack32(v)
+		if err != nil {
+			t.Fatalf("unexpected error: %v; version: %+v", err, v)
+		}
+		if i > 0 && packed <= last {
+			t.Errorf("order expectation failed, %+v packed as %v, not greater than %v", v, packed, last)
+		}
+		last = packed
+		v2, err := p.Unpack32(packed)
+		if err != nil || !v2.EQ(v) {
+			t.Errorf("unpacked expectation failed, expected: %+v, actual: %+v, %v", v, v2, err)
+		}
+	}
+}
+
+func TestPackingError(t *testing.T) {
+	cases := []*version.Packing{
+		{},
+		{Fields: []version.PackedField{{version.MinorField, 8}, {version.MajorField, 8}}},
+		{Fields: []version.PackedField{{version.MajorField, 8}, {version.MajorField, 8}}},
+		{Fields: []version.PackedField{{version.OtherField, 8}}},
+		{Fields: []version.PackedField{{version.MajorField, 0}}},
+		{Fields: []version.PackedField{{version.MajorField, 16}, {version.MinorField, 17}}},
+		{Fields: []version.PackedField{{version.MajorField, 5}, {version.MinorField, 5}}, Decimal: true},
+	}
+
+	for _, p := range cases {
+		if _, err := p.Pack32(&version.Version{}); err == nil {
+			t.Errorf("ill-formed packing accepted: %+v", p)
+		}
+		if _, err := p.Unpack32(0); err == nil {
+			t.Errorf("ill-formed packing accepted: %+v", p)
+		}
+	}
+
+	p := &version.Packing{Fields: []version.PackedField{{version.MajorField, 2}, {version.PreRelField, 1}}, Decimal: true}
+	if _, err := p.Unpack32(1003); err == nil {
+		t.Errorf("out of range packed version accepted")
+	}
+	if _, err := p.Unpack32(15); err == nil {
+		t.Errorf("unknown packed tag accepted")
+	}
+}
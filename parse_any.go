@@ -0,0 +1,102 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnyMode decides how ParseAnyMode treats a version string read by more than one layout.
+type AnyMode int
+
+const (
+	// FirstMatch returns the version read by the first layout in order.
+	FirstMatch AnyMode = iota
+	// RejectAmbiguous returns an AmbiguousError if the layouts read different versions.
+	RejectAmbiguous
+)
+
+// ParseAnyError lists why each layout fails to read a version string.
+type ParseAnyError struct {
+	VersionString string
+	Layouts       []string
+	Errs          []error
+}
+
+func (e *ParseAnyError) Error() string {
+	reasons := make([]string, len(e.Layouts))
+	for i, layout := range e.Layouts {
+		reasons[i] = fmt.Sprintf("%s: %v", layout, e.Errs[i])
+	}
+	return fmt.Sprintf("no layout reads version string %s (%s)", e.VersionString, strings.Join(reasons, "; "))
+}
+
+// AmbiguousError lists the layouts reading a version string into different versions.
+type AmbiguousError struct {
+	VersionString string
+	Indices       []int
+	Layouts       []string
+	Versions      []*Version
+}
+
+func (e *AmbiguousError) Error() string {
+	reasons := make([]string, len(e.Layouts))
+	for i, layout := range e.Layouts {
+		reasons[i] = fmt.Sprintf("%s: %+v", layout, *e.Versions[i])
+	}
+	return fmt.Sprintf("ambiguous version string %s (%s)", e.VersionString, strings.Join(reasons, "; "))
+}
+
+// ParseAny reads a version string in the first layout that reads it, in the order of priority,
+// and returns the index of the layout.
+// If no layout reads it, a ParseAnyError is returned.
+func ParseAny(layouts []string, versionString string) (*Version, int, error) {
+	return ParseAnyMode(layouts, versionString, FirstMatch)
+}
+
+// ParseAnyMode reads a version string like ParseAny, and treats a version string read by more than one layout by the mode.
+func ParseAnyMode(layouts []string, versionString string, mode AnyMode) (*Version, int, error) {
+	failed := &ParseAnyError{VersionString: versionString}
+	ambiguous := &AmbiguousError{VersionString: versionString}
+	for i, layout := range layouts {
+		v, err := Parse(layout, versionString)
+		if err != nil {
+			failed.Layouts = append(failed.Layouts, layout)
+			failed.Errs = append(failed.Errs, err)
+			continue
+		}
+		if mode == FirstMatch {
+			return v, i, nil
+		}
+		ambiguous.Indices = append(ambiguous.Indices, i)
+		ambiguous.Layouts = append(ambiguous.Layouts, layout)
+		ambiguous.Versions = append(ambiguous.Versions, v)
+	}
+	if len(ambiguous.Versions) == 0 {
+		return nil, -1, failed
+	}
+	first := ambiguous.Versions[0]
+	for _, v := range ambiguous.Versions[1:] {
+		if !v.EQ(first) || v.Other != first.Other {
+			return nil, -1, ambiguous
+		}
+	}
+	return first, ambiguous.Indices[0], nil
+}
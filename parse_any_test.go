@@ -0,0 +1,97 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestParseAny(t *testing.T) {
+	layouts := []string{"!v5.4.3", "5.4", "5.4.3b1", "5.4.3-beta.1"}
+
+	cases := []struct {
+		VersionString string
+		Expected      *version.Version
+		Index         int
+	}{
+		{
+			"v1.2.3",
+			&version.Version{Major: 1, Minor: 2, Patch: 3},
+			0,
+		},
+		{
+			"1.2",
+			&version.Version{Major: 1, Minor: 2},
+			1,
+		},
+		{
+			"1.2.3b4",
+			&version.Version{Major: 1, Minor: 2, Patch: 3, PreRel: version.Beta, Build: 4},
+			2,
+		},
+		{
+			"1.2.3-beta.4",
+			&version.Version{Major: 1, Minor: 2, Patch: 3, PreRel: version.Beta, Build: 4},
+			3,
+		},
+	}
+
+	for _, c := range cases {
+		v, i, err := version.ParseAny(layouts, c.VersionString)
+		if err != nil || i != c.Index || !v.EQ(c.Expected) {
+			t.Errorf("version expectation failed, expected: %+v at %d, actual: %+v at %d, %v; input: %v", c.Expected, c.Index, v, i, err, c.VersionString)
+		}
+	}
+
+	_, i, err := version.ParseAny(layouts, "1.2.3.4.5")
+	var anyErr *version.ParseAnyError
+	if !errors.As(err, &anyErr) || i != -1 {
+		t.Fatalf("error expectation failed, expected: %T, actual: %v at %d", anyErr, err, i)
+	}
+	if len(anyErr.Layouts) != len(layouts) || len(anyErr.Errs) != len(layouts) {
+		t.Errorf("not every layout is reported: %v", anyErr)
+	}
+}
+
+func TestParseAnyAmbiguous(t *testing.T) {
+	layouts := []string{"5.4.3b1", "5.4.3-beta.1"}
+
+	v, i, err := version.ParseAnyMode(layouts, "1.2.3b4", version.RejectAmbiguous)
+	if err != nil || i != 0 || !v.EQ(&version.Version{Major: 1, Minor: 2, Patch: 3, PreRel: version.Beta, Build: 4}) {
+		t.Errorf("layouts reading the same version rejected: %+v at %d, %v", v, i, err)
+	}
+
+	// `1.2.3b` is a beta in `5.4.3b`, or of an alphabetic build 2 in `5.4.3z`
+	layouts = []string{"5.4.3b", "5.4.3z"}
+	v, i, err = version.ParseAny(layouts, "1.2.3b")
+	if err != nil || i != 0 || v.PreRel != version.Beta {
+		t.Errorf("first layout not preferred: %+v at %d, %v", v, i, err)
+	}
+	_, i, err = version.ParseAnyMode(layouts, "1.2.3b", version.RejectAmbiguous)
+	var ambiguousErr *version.AmbiguousError
+	if !errors.As(err, &ambiguousErr) || i != -1 {
+		t.Fatalf("error expectation failed, expected: %T, actual: %v at %d", ambiguousErr, err, i)
+	}
+	if len(ambiguousErr.Indices) != 2 || ambiguousErr.Versions[1].Build != 2 {
+		t.Errorf("matching layouts not reported: %v", ambiguousErr)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+// Pattern is a version pattern, where fields may be wildcards, like `1.2.x` or `1.2.3-rc.*`.
+type Pattern struct {
+	Version  Version // the fields which are not wildcards, where wildcards are zero
+	wildcard fieldSet
+	spelling spelling
+	layout   *Layout
+}
+
+// spelling is the wildcard written for each counter field, like `X` or `*`, or 0 if not written.
+type spelling [major + 1]byte
+
+// ParsePattern reads a version pattern in the layout.
+//
+// A numeric field, including a calendar one, may be written as `x`, `X` or `*`, and so may the pre-release tag, like `1.2.3-*`,
+// while an alphabetic field may only be written as `*`, because `x` is a letter.
+// A wildcard as the last field written also matches any value of each less significant field not written,
+// e.g. `1.x` in `5.4[.3][-beta[.1]]` matches 1.5.2-beta.1,
+// and so does a pattern ended early at `$`, e.g. `1.2` in `5.4$.3` matches 1.2.3, like `1.2.x`.
+// Fields not written otherwise are zero as Parse reads them, e.g. `1.x.3` matches no pre-release.
+func ParsePattern(layout string, patternString string) (*Pattern, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return nil, err
+	}
+	return l.ParsePattern(patternString)
+}
+
+// ParsePattern reads a version pattern, like the function ParsePattern.
+func (l *Layout) ParsePattern(patternString string) (*Pattern, error) {
+	st, err := l.run(&matcher{pattern: true}, patternString)
+	if err != nil {
+		return nil, err
+	}
+	wildcard, spelling := st.wildcard, st.spelling
+	if least := leastSignificant(st.read); st.early || wildcard.has(least) {
+		for field := least - 1; field >= build; field-- {
+			if !st.read.has(field) {
+				wildcard = wildcard.with(field)
+				spelling[field] = spelling[least]
+			}
+		}
+	}
+	return &Pattern{Version: st.v, wildcard: wildcard, spelling: spelling, layout: l}, nil
+}
+
+// omittableWildcard reports whether a wildcard is read back as a wildcard if not written,
+// i.e. the field just more significant is also a wildcard, and so are all less significant fields.
+func omittableWildcard(wildcard fieldSet, field Field) bool {
+	if !wildcard.has(field + 1) {
+		return false
+	}
+	for f := field; f >= build; f-- {
+		if !wildcard.has(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// leastSignificant returns the least significant counter field in the set, or 0 if none.
+func leastSignificant(s fieldSet) Field {
+	for field := build; field <= major; field++ {
+		if s.has(field) {
+			return field
+		}
+	}
+	return 0
+}
+
+// IsWildcard reports whether a field is a wildcard, i.e. one of MajorField, MinorField, PatchField, PreRelField and BuildField.
+func (p *Pattern) IsWildcard(field Field) bool {
+	return p.wildcard.has(field)
+}
+
+// Match reports whether the version matches the pattern, i.e. each field not a wildcard is equal.
+// The other text is not compared.
+func (p *Pattern) Match(v *Version) bool {
+	for _, field := range sortKeyFields {
+		if !p.wildcard.has(field) && field.value(v) != field.value(&p.Version) {
+			return false
+		}
+	}
+	return true
+}
+
+// Interval returns the interval of the versions the pattern matches, e.g. `>=1.2.0-alpha, <1.3.0-alpha` for `1.2.x`,
+// or false if they do not make an interval, because the wildcards are not the least significant fields, e.g. `1.x.3`.
+// Counters are taken as non-negative, as they are read.
+func (p *Pattern) Interval() (Interval, bool) {
+	i := 0
+	for i < len(sortKeyFields) && !p.wildcard.has(sortKeyFields[i]) {
+		i++
+	}
+	for _, field := range sortKeyFields[i:] {
+		if !p.wildcard.has(field) {
+			return Interval{}, false
+		}
+	}
+	switch i {
+	case len(sortKeyFields):
+		return Point(&p.Version), true
+	case 0:
+		return Interval{}, true
+	}
+
+	lower := bound(&p.Version)
+	for _, field := range sortKeyFields[i:] {
+		field.SetField(lower, leastValue(field))
+	}
+	upper := *lower
+	for j := i - 1; j >= 0; j-- {
+		field := sortKeyFields[j]
+		if field == preRelTag && upper.PreRel >= Release {
+			upper.PreRel = Alpha // carry to the patch
+			continue
+		}
+		field.SetField(&upper, field.value(&upper)+1)
+		break
+	}
+	return Interval{Lower: lower, Upper: &upper}, true
+}
+
+// leastValue returns the least value of a field taken by Interval.
+func leastValue(field Field) int64 {
+	if field == preRelTag {
+		return int64(Alpha)
+	}
+	return 0
+}
+
+// String writes the pattern in the layout it is read in.
+func (p *Pattern) String() string {
+	return p.layout.FormatPattern(p)
+}
+
+// FormatPattern writes a pattern in the layout, where wildcards are written as they are read, e.g. `1.*` for `1.*`,
+// and omitted if they are read back as wildcards, e.g. `1.X` for `1.X.X` in `5.4$.3`.
+// A wildcard implied by the one before it is spelt like it, and one implied by an allowed end,
+// like the minor one of `1` in `5.4$.3`, is written as `x`, or `*` for tags.
+// A wildcard of an alphabetic field is always written as `*`.
+func (l *Layout) FormatPattern(p *Pattern) string {
+	parts, _ := formatPatternNodes(l.nodes, &p.Version, p.wildcard, &p.spelling)
+	return joinSpans(parts)
+}
+
+func isWildcardField(field Field) bool {
+	switch field {
+	case build, preRelTag, patch, minor, major, alphabetic_build, alphabetic_patch:
+		return true
+	default:
+		return isCalendar(field)
+	}
+}
+
+func isWildcard(c byte) bool {
+	return c == 'x' || c == 'X' || c == '*'
+}
+
+// readWildcard reads a wildcard for a field of the layout chunk, and returns its length and the wildcard character,
+// or 0 if it is not found.
+func readWildcard(field Field, layout string, source string) (int, byte) {
+	switch {
+	case field == preRelTag:
+		offset := 0
+		if layout[0] == '-' && len(source) > 0 && source[0] == '-' {
+			offset++
+		}
+		if offset == len(source) || !isWildcard(source[offset]) {
+			return 0, 0
+		}
+		c := source[offset]
+		offset++
+		if layout[len(layout)-1] == '-' && offset < len(source) && source[offset] == '-' {
+			offset++
+		}
+		return offset, c
+	case field == alphabetic_build || field == alphabetic_patch:
+		if len(source) > 0 && source[0] == '*' {
+			return 1, '*'
+		}
+	case isWildcardField(field):
+		if len(source) > 0 && isWildcard(source[0]) {
+			return 1, source[0]
+		}
+	}
+	return 0, 0
+}
+
+// formatWildcard writes a wildcard for a field of the layout chunk, in the spelling read if it is valid for the field.
+func formatWildcard(field Field, layout string, c byte) string {
+	switch field {
+	case preRelTag:
+		text := "*"
+		if c != 0 {
+			text = string(c)
+		}
+		if layout[0] == '-' {
+			text = "-" + text
+		}
+		if len(layout) > 1 && layout[len(layout)-1] == '-' {
+			text += "-"
+		}
+		return text
+	case alphabetic_build, alphabetic_patch:
+		return "*"
+	default:
+		if c == 0 {
+			return "x"
+		}
+		return string(c)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestParsePattern(t *testing.T) {
+	cases := []struct {
+		Layout    string
+		Pattern   string
+		Matched   []string
+		Unmatched []string
+		Written   string
+	}{
+		{"5.4.3[-beta[.1]]", "1.2.x", []string{"1.2.0", "1.2.9", "1.2.3-beta.1"}, []string{"1.3.0", "2.2.0"}, "1.2.x"},
+		{"5.4.3[-beta[.1]]", "1.2.*", []string{"1.2.7"}, []string{"1.3.7"}, "1.2.*"},
+		{"5.4.3[-beta[.1]]", "1.X.x", []string{"1.0.0", "1.9.9-rc.2"}, []string{"2.0.0"}, "1.X.x"},
+		{"5.4.3[-beta[.1]]", "1.x.3", []string{"1.0.3", "1.9.3"}, []string{"1.0.4", "1.9.3-rc.1"}, "1.x.3"},
+		{"5.4.3[-beta[.1]]", "1.2.X-rc.*", []string{"1.2.0-rc.1", "1.2.9-rc"}, []string{"1.2.0", "1.2.0-beta.1"}, "1.2.X-rc.*"},
+		{"5.4.3[-beta[.1]]", "1.2.3-*", []string{"1.2.3", "1.2.3-alpha.2", "1.2.3-rc"}, []string{"1.2.4"}, "1.2.3-*"},
+		{"5.4.3[-beta[.1]]", "1.2.3", []string{"1.2.3"}, []string{"1.2.3-rc.1", "1.2.4"}, "1.2.3"},
+		{"[v]5$.4$.3", "1", []string{"1.0.0", "1.5.2"}, []string{"2.0.0"}, "1.x"},
+		{"[v]5$.4$.3", "v1.2", []string{"1.2.0", "1.2.5"}, []string{"1.3.0"}, "1.2.x"},
+		{"[v]5$.4$.3", "1.*", []string{"1.0.0", "1.5.2"}, []string{"2.0.0"}, "1.*"},
+		{"[v]5$.4$.3", "1.X.X", []string{"1.0.0", "1.5.2"}, []string{"2.0.0"}, "1.X"},
+		{"5.4.3[-beta[.1]]", "1.2.3-x", []string{"1.2.3-rc.2"}, []string{"1.2.4"}, "1.2.3-x"},
+		{"5.4.3z", "1.2.X*", []string{"1.2.0a"}, []string{"1.3.0a"}, "1.2.X*"},
+		{"[v]5$.4$.3", "x", []string{"0.0.0", "3.2.1"}, nil, "x"},
+		{"5.4z", "1.2*", []string{"1.2", "1.2a", "1.2zz"}, []string{"1.3a"}, "1.2*"},
+		{"YYYY.0M$.0D", "2023.x", []string{"2023.01.05", "2023.12.31"}, []string{"2024.01.01"}, "2023.x"},
+	}
+
+	for _, c := range cases {
+		p, err := version.ParsePattern(c.Layout, c.Pattern)
+		if err != nil {
+			t.Errorf("unexpected error: %v; layout: %v, pattern: %v", err, c.Layout, c.Pattern)
+			continue
+		}
+		for _, s := range c.Matched {
+			if !p.Match(mustParse(t, c.Layout, s)) {
+				t.Errorf("match expectation failed, expected: %v, actual: %v; layout: %v, pattern: %v, version: %v", true, false, c.Layout, c.Pattern, s)
+			}
+		}
+		for _, s := range c.Unmatched {
+			if p.Match(mustParse(t, c.Layout, s)) {
+				t.Errorf("match expectation failed, expected: %v, actual: %v; layout: %v, pattern: %v, version: %v", false, true, c.Layout, c.Pattern, s)
+			}
+		}
+		if written := p.String(); written != c.Written {
+			t.Errorf("format expectation failed, expected: %v, actual: %v; layout: %v, pattern: %v", c.Written, written, c.Layout, c.Pattern)
+		}
+		p2, err := version.ParsePattern(c.Layout, p.String())
+		if err != nil || p2.String() != p.String() {
+			t.Errorf("round trip expectation failed, expected: %v, actual: %v, error: %v; layout: %v", p, p2, err, c.Layout)
+		}
+	}
+}
+
+func TestParsePatternError(t *testing.T) {
+	cases := []struct {
+		Layout  string
+		Pattern string
+	}{
+		{"5.4.3", "1.y.3"},
+		{"5.4z", "1.2x."},
+		{"5.4.3", "1.2"},
+		{"YYYY.0M.0D", "2023.13.x"},
+	}
+
+	for _, c := range cases {
+		if _, err := version.ParsePattern(c.Layout, c.Pattern); err == nil {
+			t.Errorf("error expected; layout: %v, pattern: %v", c.Layout, c.Pattern)
+		}
+	}
+}
+
+func TestPatternInterval(t *testing.T) {
+	cases := []struct {
+		Pattern  string
+		Expected string
+		Ok       bool
+	}{
+		{"1.2.x", ">=1.2.0-alpha, <1.3.0-alpha", true},
+		{"1.x.x", ">=1.0.0-alpha, <2.0.0-alpha", true},
+		{"1.2.3-*", ">=1.2.3-alpha, <1.2.4-alpha", true},
+		{"1.2.3-rc.*", ">=1.2.3-rc, <1.2.3", true},
+		{"1.2.3.*", ">=1.2.3, <1.2.4-alpha", true},
+		{"1.2.3", "1.2.3", true},
+		{"x.x.x", "*", true},
+		{"1.x.3", "", false},
+		{"1.2.x-rc.1", "", false},
+	}
+
+	layout := "5.4.3[(-beta|.)[.1]]"
+	for _, c := range cases {
+		p, err := version.ParsePattern(layout, c.Pattern)
+		if err != nil {
+			t.Errorf("unexpected error: %v; pattern: %v", err, c.Pattern)
+			continue
+		}
+		i, ok := p.Interval()
+		if ok != c.Ok || ok && i.String() != c.Expected {
+			t.Errorf("interval expectation failed, expected: %v %v, actual: %v %v; pattern: %v", c.Expected, c.Ok, i, ok, c.Pattern)
+		}
+		if !ok {
+			continue
+		}
+		for _, v := range []*version.Version{
+			{Major: 1, Minor: 2, Patch: 3}, {Major: 1, Minor: 2, Patch: 3, PreRel: version.ReleaseCandidate, Build: 2},
+			{Major: 1, Minor: 2, Patch: 3, Build: 5}, {Major: 1, Minor: 2, Patch: 9}, {Major: 1, Minor: 5},
+			{Major: 2}, {Major: 1, Minor: 2, Patch: 4, PreRel: version.Alpha},
+		} {
+			if i.Contains(v) != p.Match(v) {
+				t.Errorf("interval expectation failed, expected: %v, actual: %v; pattern: %v, version: %+v", p.Match(v), i.Contains(v), c.Pattern, v)
+			}
+		}
+	}
+}
+
+func TestPatternIsWildcard(t *testing.T) {
+	p, err := version.ParsePattern("5.4.3[-beta[.1]]", "1.x.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[version.Field]bool{
+		version.MajorField: false, version.MinorField: true, version.PatchField: false,
+		version.PreRelField: false, version.BuildField: false,
+	}
+	for field, wildcard := range expected {
+		if p.IsWildcard(field) != wildcard {
+			t.Errorf("wildcard expectation failed, expected: %v, actual: %v; field: %v", wildcard, p.IsWildcard(field), field)
+		}
+	}
+}
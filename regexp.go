@@ -0,0 +1,1055 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Regexp returns a regular expression accepting the version strings the layout reads, like the method Regexp.
+func Regexp(layout string) (*regexp.Regexp, string, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return nil, "", err
+	}
+	return l.Regexp()
+}
+
+// Regexp returns a regular expression accepting the version strings Parse reads, with a named group for each field,
+// i.e. major, minor, patch, pre, build, other, year, month, week or day.
+// A field may have several groups of the same name in alternatives, of which only the matching one captures it.
+// It also returns the expression with the named groups as plain groups, for engines without named groups,
+// like those of JSON Schema or SQL.
+//
+// A date is checked as a whole like Parse checks it, e.g. `YYYY.0M.0D` rejects `2023.02.30` and `2023.02.29`,
+// and `YYYY.WW` rejects `2021.53`, in a year of 52 weeks, by an alternative for each kind of months and years,
+// and an error is returned for a layout reading a field of a date more than once, like `YYYY.0M.0D.3`.
+//
+// Because a field reads as many digits or letters as it can, and an optional literal is read if found,
+// the expression excludes what would follow them without being read.
+// Continuations shared by alternatives are written once, but the expression may still grow fast
+// with optional tags in a row, like `5.4.3[-b][-b][-b]1`, and an error is returned if it is too large to compile.
+func (l *Layout) Regexp() (*regexp.Regexp, string, error) {
+	calendars, err := l.reCalendars()
+	if err != nil {
+		return nil, "", fmt.Errorf("regular expression of layout %s not exact: %v", l.source, err)
+	}
+	exprs := make([]*re, len(calendars))
+	for i := range calendars {
+		exprs[i] = reNodes(l.nodes, reEmpty, &calendars[i])
+	}
+	r := reAlt(exprs...)
+	var named, plain strings.Builder
+	named.WriteString("^")
+	r.write(&named, precConcat, true)
+	named.WriteString("$")
+	plain.WriteString("^")
+	r.write(&plain, precConcat, false)
+	plain.WriteString("$")
+	compiled, err := regexp.Compile(named.String())
+	if err != nil {
+		return nil, "", fmt.Errorf("regular expression of layout %s not compiled: %v", l.source, err)
+	}
+	return compiled, plain.String(), nil
+}
+
+// runeClass is a set of runes, where runes out of ASCII are either all included or all excluded.
+type runeClass struct {
+	ascii    [utf8.RuneSelf]bool
+	nonASCII bool
+}
+
+func classOf(runes ...rune) *runeClass {
+	c := &runeClass{}
+	for _, r := range runes {
+		c.add(r)
+	}
+	return c
+}
+
+func classRange(lo rune, hi rune) *runeClass {
+	c := &runeClass{}
+	for r := lo; r <= hi; r++ {
+		c.add(r)
+	}
+	return c
+}
+
+func (c *runeClass) add(r rune) {
+	if r < utf8.RuneSelf {
+		c.ascii[r] = true
+	} else {
+		c.nonASCII = true
+	}
+}
+
+func (c *runeClass) has(r rune) bool {
+	if r < utf8.RuneSelf {
+		return c.ascii[r]
+	}
+	return c.nonASCII
+}
+
+func (c *runeClass) union(c2 *runeClass) *runeClass {
+	u := *c
+	for r := range u.ascii {
+		u.ascii[r] = u.ascii[r] || c2.ascii[r]
+	}
+	u.nonASCII = u.nonASCII || c2.nonASCII
+	return &u
+}
+
+func (c *runeClass) minus(c2 *runeClass) *runeClass {
+	d := *c
+	for r := range d.ascii {
+		d.ascii[r] = d.ascii[r] && !c2.ascii[r]
+	}
+	d.nonASCII = d.nonASCII && !c2.nonASCII
+	return &d
+}
+
+func (c *runeClass) isEmpty() bool {
+	return *c == runeClass{}
+}
+
+func (c *runeClass) intersects(c2 *runeClass) bool {
+	return !c.minus(c.minus(c2)).isEmpty()
+}
+
+// anyRune is the class of all runes.
+var anyRune = func() *runeClass {
+	c := &runeClass{nonASCII: true}
+	for r := range c.ascii {
+		c.ascii[r] = true
+	}
+	return c
+}()
+
+var (
+	digits  = classRange('0', '9')
+	letters = classRange('a', 'z').union(classRange('A', 'Z'))
+)
+
+// reKind is the kind of a node of a regular expression.
+type reKind int
+
+const (
+	reKindNone    reKind = iota // matching nothing
+	reKindEmpty                 // matching the empty string only
+	reKindLiteral               // a non-empty literal
+	reKindClass
+	reKindConcat
+	reKindAlt
+	reKindRepeat
+	reKindCapture
+)
+
+// re is a regular expression, which is simplified as it is built, and is never modified after built,
+// except for the results kept of its methods.
+type re struct {
+	kind  reKind
+	runes []rune     // the literal
+	class *runeClass // the class
+	subs  []*re      // the parts, the alternatives, or the one repeated or captured
+	min   int        // the least count of repetitions
+	max   int        // the greatest count of repetitions, or -1 if unbounded
+	name  string     // the name of the group
+
+	// results of nonEmpty and restrict, kept so that the same continuation is restricted into the same expression
+	nonEmptied *re
+	restricted map[runeClass]*re
+}
+
+var (
+	reNone  = &re{kind: reKindNone}
+	reEmpty = &re{kind: reKindEmpty}
+)
+
+func reLiteral(s string) *re {
+	if s == "" {
+		return reEmpty
+	}
+	return &re{kind: reKindLiteral, runes: []rune(s)}
+}
+
+func reClass(c *runeClass) *re {
+	if c.isEmpty() {
+		return reNone
+	}
+	return &re{kind: reKindClass, class: c}
+}
+
+// reConcat returns the concatenation of the parts, where the last part is kept as it is, so that reSplitTail finds it.
+func reConcat(parts ...*re) *re {
+	kept := make([]*re, 0, len(parts))
+	for _, p := range parts {
+		switch p.kind {
+		case reKindNone:
+			return reNone
+		case reKindEmpty:
+			continue
+		}
+		kept = append(kept, p)
+	}
+	switch len(kept) {
+	case 0:
+		return reEmpty
+	case 1:
+		return kept[0]
+	}
+	if len(kept) > 2 {
+		return &re{kind: reKindConcat, subs: []*re{reConcat(kept[:len(kept)-1]...), kept[len(kept)-1]}}
+	}
+	return &re{kind: reKindConcat, subs: kept}
+}
+
+func reAlt(alts ...*re) *re {
+	flat := make([]*re, 0, len(alts))
+	nullable := false
+	for _, a := range alts {
+		switch a.kind {
+		case reKindNone:
+			continue
+		case reKindEmpty:
+			nullable = true
+			continue
+		case reKindAlt:
+			flat = append(flat, a.subs...)
+			continue
+		}
+		if a.kind == reKindRepeat && a.min == 0 && a.max == 1 {
+			nullable = true
+			a = a.subs[0]
+		}
+		flat = append(flat, a)
+	}
+	kept := make([]*re, 0, len(flat))
+	for _, a := range reFactorTails(reFactorHeads(flat)) {
+		duplicate := false
+		for _, k := range kept {
+			duplicate = duplicate || k == a
+		}
+		if !duplicate {
+			kept = append(kept, a)
+		}
+	}
+	var r *re
+	switch len(kept) {
+	case 0:
+		if nullable {
+			return reEmpty
+		}
+		return reNone
+	case 1:
+		r = kept[0]
+	default:
+		r = &re{kind: reKindAlt, subs: kept}
+	}
+	if nullable && !r.nullable() {
+		return reRepeat(r, 0, 1)
+	}
+	return r
+}
+
+// reFactorHeads returns the alternatives with those starting with equal parts joined into one.
+func reFactorHeads(alts []*re) []*re {
+	for i := 0; i < len(alts); i++ {
+		head, _ := alts[i].splitHead()
+		rests := make([]*re, 0, 1)
+		others := make([]*re, 0, len(alts))
+		for j, a := range alts {
+			if j < i {
+				continue
+			}
+			if h, rest := a.splitHead(); h.equal(head) {
+				rests = append(rests, rest)
+			} else {
+				others = append(others, a)
+			}
+		}
+		if len(rests) > 1 {
+			factored := append(append(alts[:i:i], reConcat(head, reAlt(rests...))), others...)
+			return reFactorHeads(factored)
+		}
+	}
+	return alts
+}
+
+// reFactorTails returns the alternatives with those ending with a shared tail joined into one,
+// so that a continuation copied into several alternatives is written once.
+func reFactorTails(alts []*re) []*re {
+	for i := 0; i < len(alts); i++ {
+		for tail := alts[i]; tail.kind == reKindConcat; {
+			tail = tail.subs[1]
+			heads := []*re{reEmpty}
+			heads[0], _ = alts[i].splitTail(tail)
+			rest := make([]*re, 0, len(alts))
+			for j, a := range alts {
+				if j <= i {
+					continue
+				}
+				if head, ok := a.splitTail(tail); ok {
+					heads = append(heads, head)
+				} else {
+					rest = append(rest, a)
+				}
+			}
+			if len(heads) > 1 {
+				factored := append(append(alts[:i:i], reConcat(reAlt(heads...), tail)), rest...)
+				return reFactorTails(factored)
+			}
+		}
+	}
+	return alts
+}
+
+func reRepeat(sub *re, min int, max int) *re {
+	switch {
+	case max == 0 || sub.kind == reKindEmpty:
+		return reEmpty
+	case sub.kind == reKindNone:
+		if min == 0 {
+			return reEmpty
+		}
+		return reNone
+	case min == 1 && max == 1:
+		return sub
+	}
+	return &re{kind: reKindRepeat, subs: []*re{sub}, min: min, max: max}
+}
+
+func reCapture(name string, sub *re) *re {
+	if sub.kind == reKindNone {
+		return reNone
+	}
+	return &re{kind: reKindCapture, subs: []*re{sub}, name: name}
+}
+
+// rest returns the repetitions after the first one.
+func (r *re) rest() *re {
+	min, max := r.min-1, r.max
+	if min < 0 {
+		min = 0
+	}
+	if max > 0 {
+		max--
+	}
+	return reRepeat(r.subs[0], min, max)
+}
+
+// nullable reports whether the expression matches the empty string.
+func (r *re) nullable() bool {
+	switch r.kind {
+	case reKindEmpty:
+		return true
+	case reKindConcat:
+		for _, sub := range r.subs {
+			if !sub.nullable() {
+				return false
+			}
+		}
+		return true
+	case reKindAlt:
+		for _, sub := range r.subs {
+			if sub.nullable() {
+				return true
+			}
+		}
+		return false
+	case reKindRepeat:
+		return r.min == 0 || r.subs[0].nullable()
+	case reKindCapture:
+		return r.subs[0].nullable()
+	default:
+		return false
+	}
+}
+
+// first returns the runes a non-empty string matched by the expression may start with.
+func (r *re) first() *runeClass {
+	switch r.kind {
+	case reKindLiteral:
+		return classOf(r.runes[0])
+	case reKindClass:
+		return r.class
+	case reKindConcat:
+		c := &runeClass{}
+		for _, sub := range r.subs {
+			c = c.union(sub.first())
+			if !sub.nullable() {
+				break
+			}
+		}
+		return c
+	case reKindAlt:
+		c := &runeClass{}
+		for _, sub := range r.subs {
+			c = c.union(sub.first())
+		}
+		return c
+	case reKindRepeat, reKindCapture:
+		return r.subs[0].first()
+	default:
+		return &runeClass{}
+	}
+}
+
+// nonEmpty returns the expression without the empty string.
+func (r *re) nonEmpty() *re {
+	if !r.nullable() {
+		return r
+	}
+	if r.nonEmptied == nil {
+		r.nonEmptied = r.computeNonEmpty()
+	}
+	return r.nonEmptied
+}
+
+func (r *re) computeNonEmpty() *re {
+	switch r.kind {
+	case reKindConcat:
+		head, tail := r.subs[0], r.subs[1]
+		return reAlt(reConcat(head.nonEmpty(), tail), tail.nonEmpty())
+	case reKindAlt:
+		alts := make([]*re, len(r.subs))
+		for i, sub := range r.subs {
+			alts[i] = sub.nonEmpty()
+		}
+		return reAlt(alts...)
+	case reKindRepeat:
+		return reConcat(r.subs[0].nonEmpty(), r.rest())
+	case reKindCapture:
+		return reCapture(r.name, r.subs[0].nonEmpty())
+	default:
+		return reNone
+	}
+}
+
+// restrict returns the expression without the strings starting with a rune in the class.
+func (r *re) restrict(c *runeClass) *re {
+	if !r.first().intersects(c) {
+		return r
+	}
+	if restricted, ok := r.restricted[*c]; ok {
+		return restricted
+	}
+	if r.restricted == nil {
+		r.restricted = make(map[runeClass]*re)
+	}
+	restricted := r.computeRestrict(c)
+	r.restricted[*c] = restricted
+	return restricted
+}
+
+func (r *re) computeRestrict(c *runeClass) *re {
+	switch r.kind {
+	case reKindLiteral:
+		return reNone
+	case reKindClass:
+		return reClass(r.class.minus(c))
+	case reKindConcat:
+		head, tail := r.subs[0], r.subs[1]
+		restricted := reConcat(head.nonEmpty().restrict(c), tail)
+		if head.nullable() {
+			return reAlt(restricted, tail.restrict(c))
+		}
+		return restricted
+	case reKindAlt:
+		alts := make([]*re, len(r.subs))
+		for i, sub := range r.subs {
+			alts[i] = sub.restrict(c)
+		}
+		return reAlt(alts...)
+	case reKindRepeat:
+		restricted := reConcat(r.subs[0].nonEmpty().restrict(c), r.rest())
+		if r.nullable() {
+			return reAlt(reEmpty, restricted)
+		}
+		return restricted
+	case reKindCapture:
+		return reCapture(r.name, r.subs[0].restrict(c))
+	default:
+		return r
+	}
+}
+
+// derive returns the expression matching what follows the rune in the strings matched by the expression starting with it.
+// Groups are dropped, because the rune is taken out of them.
+func (r *re) derive(c rune) *re {
+	switch r.kind {
+	case reKindLiteral:
+		if r.runes[0] != c {
+			return reNone
+		}
+		return reLiteral(string(r.runes[1:]))
+	case reKindClass:
+		if !r.class.has(c) {
+			return reNone
+		}
+		return reEmpty
+	case reKindConcat:
+		head, tail := r.subs[0], r.subs[1]
+		derived := reConcat(head.derive(c), tail)
+		if head.nullable() {
+			return reAlt(derived, tail.derive(c))
+		}
+		return derived
+	case reKindAlt:
+		alts := make([]*re, len(r.subs))
+		for i, sub := range r.subs {
+			alts[i] = sub.derive(c)
+		}
+		return reAlt(alts...)
+	case reKindRepeat:
+		return reConcat(r.subs[0].derive(c), r.rest())
+	case reKindCapture:
+		return r.subs[0].derive(c)
+	default:
+		return reNone
+	}
+}
+
+// without returns the expression without the strings starting with any of the prefixes.
+func (r *re) without(prefixes ...string) *re {
+	starts := &runeClass{}
+	order := make([]rune, 0, len(prefixes))
+	rests := make(map[rune][]string)
+	for _, prefix := range prefixes {
+		c, size := utf8.DecodeRuneInString(prefix)
+		if !starts.has(c) {
+			starts.add(c)
+			order = append(order, c)
+		}
+		rests[c] = append(rests[c], prefix[size:])
+	}
+	if !r.first().intersects(starts) {
+		return r
+	}
+	// without the strings starting with the first runes, or with the runes followed by none of the rest of the prefixes
+	alts := []*re{r.restrict(starts)}
+	for _, c := range order {
+		partial := r.first().has(c)
+		for _, rest := range rests[c] {
+			partial = partial && rest != ""
+		}
+		if partial {
+			alts = append(alts, reConcat(reLiteral(string(c)), r.derive(c).without(rests[c]...)))
+		}
+	}
+	return reAlt(alts...)
+}
+
+// splitTail returns the expression before the tail, if the expression is a concatenation ending with it.
+func (r *re) splitTail(tail *re) (*re, bool) {
+	if r == tail {
+		return reEmpty, true
+	}
+	if r.kind != reKindConcat {
+		return nil, false
+	}
+	head, ok := r.subs[1].splitTail(tail)
+	if !ok {
+		return nil, false
+	}
+	return reConcat(r.subs[0], head), true
+}
+
+// splitHead returns the first part of the expression and the rest after it.
+func (r *re) splitHead() (*re, *re) {
+	if r.kind != reKindConcat {
+		return r, reEmpty
+	}
+	head, rest := r.subs[0].splitHead()
+	return head, reConcat(rest, r.subs[1])
+}
+
+// equal reports whether the expressions are the same in structure.
+func (r *re) equal(r2 *re) bool {
+	if r == r2 {
+		return true
+	}
+	if r.kind != r2.kind || r.min != r2.min || r.max != r2.max || r.name != r2.name ||
+		len(r.runes) != len(r2.runes) || len(r.subs) != len(r2.subs) {
+		return false
+	}
+	for i := range r.runes {
+		if r.runes[i] != r2.runes[i] {
+			return false
+		}
+	}
+	if r.class != nil && *r.class != *r2.class {
+		return false
+	}
+	for i := range r.subs {
+		if !r.subs[i].equal(r2.subs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// precedences of the contexts an expression is written in
+const (
+	precAlt = iota
+	precConcat
+	precRepeat
+)
+
+// write writes the expression, in a group if the context binds tighter, and with names of groups if named is set.
+func (r *re) write(b *strings.Builder, prec int, named bool) {
+	switch r.kind {
+	case reKindNone:
+		b.WriteString(`[^\s\S]`)
+	case reKindEmpty:
+		if prec == precRepeat {
+			b.WriteString("(?:)")
+		}
+	case reKindLiteral:
+		if prec == precRepeat && len(r.runes) > 1 {
+			b.WriteString("(?:" + regexp.QuoteMeta(string(r.runes)) + ")")
+		} else {
+			b.WriteString(regexp.QuoteMeta(string(r.runes)))
+		}
+	case reKindClass:
+		r.class.write(b)
+	case reKindConcat:
+		if prec == precRepeat {
+			b.WriteString("(?:")
+		}
+		r.subs[0].write(b, precConcat, named)
+		r.subs[1].write(b, precConcat, named)
+		if prec == precRepeat {
+			b.WriteString(")")
+		}
+	case reKindAlt:
+		if prec != precAlt {
+			b.WriteString("(?:")
+		}
+		for i, sub := range r.subs {
+			if i > 0 {
+				b.WriteString("|")
+			}
+			sub.write(b, precAlt, named)
+		}
+		if prec != precAlt {
+			b.WriteString(")")
+		}
+	case reKindRepeat:
+		r.subs[0].write(b, precRepeat, named)
+		switch {
+		case r.min == 0 && r.max == 1:
+			b.WriteString("?")
+		case r.min == 0 && r.max == -1:
+			b.WriteString("*")
+		case r.min == 1 && r.max == -1:
+			b.WriteString("+")
+		case r.min == r.max:
+			fmt.Fprintf(b, "{%d}", r.min)
+		case r.max == -1:
+			fmt.Fprintf(b, "{%d,}", r.min)
+		default:
+			fmt.Fprintf(b, "{%d,%d}", r.min, r.max)
+		}
+	case reKindCapture:
+		if named {
+			b.WriteString("(?P<" + r.name + ">")
+		} else {
+			b.WriteString("(")
+		}
+		r.subs[0].write(b, precAlt, named)
+		b.WriteString(")")
+	}
+}
+
+// write writes the class in brackets, or as a rune if it is the only one.
+func (c *runeClass) write(b *strings.Builder) {
+	if *c == *anyRune {
+		b.WriteString(`[\s\S]`)
+		return
+	}
+	set, negated := c.ascii, false
+	if c.nonASCII {
+		negated = true
+		for r := range set {
+			set[r] = !set[r]
+		}
+	}
+	count := 0
+	var only rune
+	for r, in := range set {
+		if in {
+			count++
+			only = rune(r)
+		}
+	}
+	if !negated && count == 1 {
+		b.WriteString(regexp.QuoteMeta(string(only)))
+		return
+	}
+	b.WriteString("[")
+	if negated {
+		b.WriteString("^")
+	}
+	for lo := 0; lo < len(set); lo++ {
+		if !set[lo] {
+			continue
+		}
+		hi := lo
+		for hi+1 < len(set) && set[hi+1] {
+			hi++
+		}
+		writeClassRune(b, rune(lo))
+		if hi > lo {
+			if hi > lo+1 {
+				b.WriteString("-")
+			}
+			writeClassRune(b, rune(hi))
+		}
+		lo = hi
+	}
+	b.WriteString("]")
+}
+
+func writeClassRune(b *strings.Builder, r rune) {
+	switch {
+	case r < ' ' || r == 0x7f:
+		fmt.Fprintf(b, `\x%02X`, r)
+	case strings.ContainsRune(`\]^-[`, r):
+		b.WriteString(`\` + string(r))
+	default:
+		b.WriteRune(r)
+	}
+}
+
+// reNodes returns the expression of the nodes followed by the continuation, as the matcher reads them,
+// with the calendar fields in the ranges of the calendar.
+func reNodes(nodes []node, k *re, cal *reCalendar) *re {
+	for i := len(nodes) - 1; i >= 0; i-- {
+		k = reNode(&nodes[i], k, cal)
+	}
+	return k
+}
+
+// reAlternatives returns the expressions of the alternatives followed by the continuation,
+// with the continuation factored out if every alternative ends with it.
+func reAlternatives(alts [][]node, k *re, optional bool, cal *reCalendar) *re {
+	exprs := make([]*re, len(alts))
+	heads := make([]*re, len(alts))
+	factored := true
+	for i, alt := range alts {
+		exprs[i] = reNodes(alt, k, cal)
+		if head, ok := exprs[i].splitTail(k); ok {
+			heads[i] = head
+		} else {
+			factored = false
+		}
+	}
+	if factored {
+		if optional {
+			heads = append(heads, reEmpty)
+		}
+		return reConcat(reAlt(heads...), k)
+	}
+	if optional {
+		exprs = append(exprs, k)
+	}
+	return reAlt(exprs...)
+}
+
+func reNode(n *node, k *re, cal *reCalendar) *re {
+	switch n.field {
+	case allowEnd:
+		return reAlt(reEmpty, k.nonEmpty())
+	case group:
+		return reAlternatives(n.alts, k, true, cal)
+	case choice:
+		return reAlternatives(n.alts, k, false, cal)
+	case fixed:
+		// read if found, so that the rest is not read from the literal
+		if without := k.without(n.text); without != k {
+			return reAlt(reConcat(reLiteral(n.text), k), without)
+		}
+		return reConcat(reRepeat(reLiteral(n.text), 0, 1), k)
+	case required:
+		return reConcat(reLiteral(n.text), k)
+	case other:
+		if k.nullable() {
+			return reCapture("other", reRepeat(reClass(anyRune), 0, -1))
+		}
+		return reNone
+	case build, patch, minor, major:
+		return reConcat(reCapture(reFieldName(n.field), reUpTo(math.MaxInt64, true)), k.restrict(digits))
+	case year:
+		return reConcat(reCapture("year", reYear(math.MaxInt64, cal.years)), k.restrict(digits))
+	case short_year:
+		// a short year is 2000 less than the year, which is the same in the 400 years the calendar repeats in
+		return reConcat(reCapture("year", reYear(math.MaxInt64-2000, cal.years)), k.restrict(digits))
+	case month:
+		return reConcat(reCapture("month", reValues(cal.months)), k.restrict(digits))
+	case week:
+		return reConcat(reCapture("week", reUpTo(cal.weeks, false)), k.restrict(digits))
+	case day:
+		return reConcat(reCapture("day", reUpTo(cal.days, false)), k.restrict(digits))
+	case alphabetic_build, alphabetic_patch:
+		return reConcat(reCapture(reFieldName(slotOf(n.field)), reRepeat(reClass(letters), 0, -1)), k.restrict(letters))
+	case preRelTag:
+		return reTag(n.text, k)
+	default:
+		panic("unexpected field in regular expression")
+	}
+}
+
+func reFieldName(field Field) string {
+	return map[Field]string{build: "build", patch: "patch", minor: "minor", major: "major"}[field]
+}
+
+// reUpTo returns the expression of decimal numbers not greater than the maximum, with leading zeros,
+// and including zero if set.
+func reUpTo(max int64, zero bool) *re {
+	limit := strconv.FormatInt(max, 10)
+	alts := make([]*re, 0)
+	if len(limit) > 1 {
+		alts = append(alts, reConcat(reClass(classRange('1', '9')), reRepeat(reClass(digits), 0, len(limit)-2)))
+	}
+	for i := range limit {
+		lo := byte('0')
+		if i == 0 {
+			lo = '1'
+		}
+		if limit[i] > lo {
+			alts = append(alts, reConcat(reLiteral(limit[:i]), reClass(classRange(rune(lo), rune(limit[i]-1))),
+				reRepeat(reClass(digits), len(limit)-i-1, len(limit)-i-1)))
+		}
+	}
+	alts = append(alts, reLiteral(limit))
+	positive := reAlt(alts...)
+	if zero {
+		return reAlt(reRepeat(reLiteral("0"), 1, -1), reConcat(reRepeat(reLiteral("0"), 0, -1), positive))
+	}
+	return reConcat(reRepeat(reLiteral("0"), 0, -1), positive)
+}
+
+// reCalendar is the ranges of calendar fields in an alternative of the expression,
+// e.g. the months of 30 days, where a day is up to 30.
+type reCalendar struct {
+	months []int64
+	days   int64
+	weeks  int64
+	years  func(year int64) bool // whether a year in [0, 400) is in the range, or nil for any year
+}
+
+// reCalendars returns the ranges of calendar fields, one for each kind of months and years a date of the layout is checked in,
+// or an error if a field of a date may be read more than once, which the ranges do not tell apart.
+func (l *Layout) reCalendars() ([]reCalendar, error) {
+	var calendar fieldSet
+	for _, field := range l.fields() {
+		if isCalendar(field) {
+			calendar = calendar.with(field)
+		}
+	}
+	hasYear := calendar.has(year) || calendar.has(short_year)
+	checkDays := calendar.has(month) && calendar.has(day)
+	checkWeeks := calendar.has(week) && hasYear
+	if checkDays || checkWeeks {
+		reads := slotReads(l.nodes)
+		for _, field := range []Field{year, short_year, month, week, day} {
+			if slot := slotOf(field); calendar.has(field) && reads[slot] > 1 {
+				return nil, fmt.Errorf("the %v read more than once", slot)
+			}
+		}
+	}
+
+	calendars := []reCalendar{{months: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, days: 31, weeks: 53}}
+	if checkDays {
+		calendars = []reCalendar{
+			{months: []int64{1, 3, 5, 7, 8, 10, 12}, days: 31, weeks: 53},
+			{months: []int64{4, 6, 9, 11}, days: 30, weeks: 53},
+		}
+		if hasYear {
+			calendars = append(calendars,
+				reCalendar{months: []int64{2}, days: 29, weeks: 53, years: isLeapYear},
+				reCalendar{months: []int64{2}, days: 28, weeks: 53, years: func(y int64) bool { return !isLeapYear(y) }})
+		} else {
+			calendars = append(calendars, reCalendar{months: []int64{2}, days: 29, weeks: 53})
+		}
+	}
+	if checkWeeks {
+		split := make([]reCalendar, 0, 2*len(calendars))
+		for _, cal := range calendars {
+			long, short := cal, cal
+			long.years = yearsBoth(cal.years, func(y int64) bool { return isoWeeksInYear(y) == 53 })
+			short.years = yearsBoth(cal.years, func(y int64) bool { return isoWeeksInYear(y) != 53 })
+			short.weeks = 52
+			split = append(split, long, short)
+		}
+		calendars = split
+	}
+	return calendars, nil
+}
+
+func yearsBoth(f func(year int64) bool, g func(year int64) bool) func(year int64) bool {
+	if f == nil {
+		return g
+	}
+	return func(y int64) bool {
+		return f(y) && g(y)
+	}
+}
+
+// slotReads returns the most times each counter field is read on a path through the nodes.
+func slotReads(nodes []node) [major + 1]int {
+	var reads [major + 1]int
+	for _, n := range nodes {
+		if n.alts == nil {
+			if slot := slotOf(n.field); slot <= major {
+				reads[slot]++
+			}
+			continue
+		}
+		var most [major + 1]int
+		for _, alt := range n.alts {
+			altReads := slotReads(alt)
+			for slot := range most {
+				if altReads[slot] > most[slot] {
+					most[slot] = altReads[slot]
+				}
+			}
+		}
+		for slot := range reads {
+			reads[slot] += most[slot]
+		}
+	}
+	return reads
+}
+
+// reValues returns the expression of the decimal numbers of the values, with leading zeros.
+func reValues(values []int64) *re {
+	alts := make([]*re, len(values))
+	for i, value := range values {
+		alts[i] = reLiteral(strconv.FormatInt(value, 10))
+	}
+	return reConcat(reRepeat(reLiteral("0"), 0, -1), reAlt(alts...))
+}
+
+// reYear returns the expression of decimal numbers not greater than the maximum, with leading zeros,
+// and of the years in the range if it is not nil.
+// Since the calendar repeats in 400 years, and 10000 is a multiple of 400, the last four digits decide a year is in the range.
+func reYear(max int64, years func(year int64) bool) *re {
+	if years == nil {
+		return reUpTo(max, true)
+	}
+	in := func(value int64) bool {
+		return years(value % 400)
+	}
+	limit := strconv.FormatInt(max, 10)
+	head, tail := limit[:len(limit)-4], limit[len(limit)-4:]
+	last := reDigits(4, 0, in)
+	alts := []*re{
+		// fewer digits than the maximum, apart from leading zeros
+		reConcat(reRepeat(reLiteral("0"), 0, -1), reRepeat(reConcat(reClass(classRange('1', '9')), reRepeat(reClass(digits), 0, len(head)-2)), 0, 1), last),
+		reDigits(1, 0, in),
+		reDigits(2, 0, in),
+		reDigits(3, 0, in),
+		// as many digits as the maximum, which is not exceeded before the last four digits
+		reConcat(reRepeat(reLiteral("0"), 0, -1), reBelow(head), last),
+		reConcat(reRepeat(reLiteral("0"), 0, -1), reLiteral(head), reDigits(4, 0, func(value int64) bool {
+			return strconv.FormatInt(10000+value, 10)[1:] <= tail && in(value)
+		})),
+	}
+	return reAlt(alts...)
+}
+
+// reDigits returns the expression of the numbers of the width, with leading zeros, which make a value accepted after the prefix,
+// where digits leading to the same expression are written as a class.
+func reDigits(width int, prefix int64, accept func(value int64) bool) *re {
+	if width == 0 {
+		if accept(prefix) {
+			return reEmpty
+		}
+		return reNone
+	}
+	rests := make([]*re, 0, 10)
+	classes := make([]*runeClass, 0, 10)
+	for d := int64(0); d < 10; d++ {
+		rest := reDigits(width-1, prefix*10+d, accept)
+		found := false
+		for i := range rests {
+			if rests[i].equal(rest) {
+				classes[i].add(rune('0' + d))
+				found = true
+				break
+			}
+		}
+		if !found {
+			rests = append(rests, rest)
+			classes = append(classes, classOf(rune('0'+d)))
+		}
+	}
+	alts := make([]*re, len(rests))
+	for i := range rests {
+		alts[i] = reConcat(reClass(classes[i]), rests[i])
+	}
+	return reAlt(alts...)
+}
+
+// reBelow returns the expression of the numbers of the same width less than the limit, without leading zeros.
+func reBelow(limit string) *re {
+	alts := make([]*re, 0, len(limit))
+	for i := range limit {
+		lo := byte('0')
+		if i == 0 {
+			lo = '1'
+		}
+		if limit[i] > lo {
+			alts = append(alts, reConcat(reLiteral(limit[:i]), reClass(classRange(rune(lo), rune(limit[i]-1))),
+				reRepeat(reClass(digits), len(limit)-i-1, len(limit)-i-1)))
+		}
+	}
+	return reAlt(alts...)
+}
+
+// reTag returns the expression of a tag of the layout chunk followed by the continuation, as readTag reads it,
+// where the dashes and the name are read if found.
+func reTag(layout string, k *re) *re {
+	possessive := func(literal string, read *re, notRead *re) *re {
+		return reAlt(reConcat(reLiteral(literal), read), notRead.without(literal))
+	}
+	afterName := k
+	if len(layout) > 1 && layout[len(layout)-1] == '-' {
+		afterName = possessive("-", k, k)
+	}
+	style := strings.Trim(layout, "-")
+	names := make([]*re, 0, 3)
+	texts := make([]string, 0, 3)
+	for _, tag := range []PreRelTag{Alpha, Beta, ReleaseCandidate} {
+		name := formatTag(style, tag)
+		names = append(names, reLiteral(name))
+		texts = append(texts, name)
+	}
+	afterDash := reAlt(reConcat(reCapture("pre", reAlt(names...)), afterName), afterName.without(texts...))
+	if layout[0] == '-' {
+		return possessive("-", afterDash, afterDash)
+	}
+	return afterDash
+}
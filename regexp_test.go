@@ -0,0 +1,240 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+// submatch returns the text captured by a group of the name, which may be one of several groups of the same name.
+func submatch(names []string, match []string, name string) string {
+	for i, n := range names {
+		if n == name && match[i] != "" {
+			return match[i]
+		}
+	}
+	return ""
+}
+
+func TestRegexp(t *testing.T) {
+	cases := []struct {
+		Layout   string
+		Input    string
+		Expected map[string]string // the groups captured, or nil if not matched
+	}{
+		{"5.4.3", "1.2.3", map[string]string{"major": "1", "minor": "2", "patch": "3"}},
+		{"5.4.3", "1.2", nil},
+		{"5.4.3", "1.2.9223372036854775807", map[string]string{"patch": "9223372036854775807"}},
+		{"5.4.3", "1.2.9223372036854775808", nil},
+		{"5.4.3", "1.2.00009223372036854775807", map[string]string{"patch": "00009223372036854775807"}},
+		{"[v]5$.4$.3[-beta[.1]]['+'o]", "v1", map[string]string{"major": "1"}},
+		{"[v]5$.4$.3[-beta[.1]]['+'o]", "1.2.3-rc.4+linux", map[string]string{"major": "1", "pre": "rc", "build": "4", "other": "linux"}},
+		{"[v]5$.4$.3[-beta[.1]]['+'o]", "1.2.3-", map[string]string{"patch": "3"}},
+		{"[v]5$.4$.3[-beta[.1]]['+'o]", "1.2.", nil},
+		{"5.4z", "1.2ab", map[string]string{"build": "ab"}},
+		{"5.4z", "1.2", map[string]string{"minor": "2"}},
+		{"5.4z", "1.2a1", nil},
+		{"YYYY.0M.0D", "2023.12.31", map[string]string{"year": "2023", "month": "12", "day": "31"}},
+		{"YYYY.0M.0D", "2023.13.01", nil},
+		{"YYYY.0M.0D", "2023.02.30", nil},
+		{"YYYY.0M.0D", "2023.04.31", nil},
+		{"YYYY.0M.0D", "2023.02.29", nil},
+		{"YYYY.0M.0D", "2024.02.29", map[string]string{"year": "2024", "day": "29"}},
+		{"YYYY.0M.0D", "2000.02.29", map[string]string{"year": "2000"}},
+		{"YYYY.0M.0D", "1900.02.29", nil},
+		{"YYYY.0M.0D", "9223372036854775804.02.29", map[string]string{"year": "9223372036854775804"}},
+		{"YYYY.0M.0D", "9223372036854775808.01.01", nil},
+		{"0Y.0M.0D", "24.02.29", map[string]string{"year": "24"}},
+		{"0Y.0M.0D", "100.02.29", nil},
+		{"MM.DD", "2.29", map[string]string{"month": "2", "day": "29"}},
+		{"MM.DD", "2.30", nil},
+		{"YYYY.WW", "2020.53", map[string]string{"week": "53"}},
+		{"YYYY.WW", "2021.53", nil},
+		{"YYYY.WW", "2021.52", map[string]string{"week": "52"}},
+		{"WW", "53", map[string]string{"week": "53"}},
+		{"5[.4]3", "1.23", nil},
+		{"5.4.3(-b|.b)1", "1.2.3.b4", map[string]string{"pre": "b", "build": "4"}},
+		{"v{major}.{minor}[.{patch}]{-pre}{.build}", "v1.2-beta.3", map[string]string{"minor": "2", "pre": "beta", "build": "3"}},
+		{"5.4.3'.'!'final'", "1.2.3final", map[string]string{"patch": "3"}},
+		{"5.4.3'.'!'final'", "1.2.3.", nil},
+	}
+
+	for _, c := range cases {
+		r, _, err := version.Regexp(c.Layout)
+		if err != nil {
+			t.Errorf("unexpected error: %v; layout: %v", err, c.Layout)
+			continue
+		}
+		match := r.FindStringSubmatch(c.Input)
+		if (match != nil) != (c.Expected != nil) {
+			t.Errorf("match expectation failed, expected: %v, actual: %v; layout: %v, input: %v", c.Expected != nil, match != nil, c.Layout, c.Input)
+			continue
+		}
+		for name, expected := range c.Expected {
+			if actual := submatch(r.SubexpNames(), match, name); actual != expected {
+				t.Errorf("group expectation failed, expected: %v, actual: %v; layout: %v, input: %v, group: %v", expected, actual, c.Layout, c.Input, name)
+			}
+		}
+	}
+}
+
+func TestRegexpPlain(t *testing.T) {
+	r, plain, err := version.Regexp("[v]5$.4$.3[-beta[.1]]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(plain, "?P<") {
+		t.Errorf("plain expression expected without named groups, actual: %v", plain)
+	}
+	if unnamed := regexp.MustCompile(`\?P<\w+>`).ReplaceAllString(r.String(), ""); unnamed != plain {
+		t.Errorf("plain expression expectation failed, expected: %v, actual: %v", unnamed, plain)
+	}
+}
+
+func TestRegexpSize(t *testing.T) {
+	cases := []struct {
+		Layout   string
+		Expected int // the greatest length expected of the plain expression
+	}{
+		{"5.4" + strings.Repeat("[.1]", 8), 10000},
+		{"5.4.3" + strings.Repeat("[.y]", 8), 3000},
+		{"5.4.3" + strings.Repeat("[-b]", 6) + "1", 20000},
+		{"5.4.3" + strings.Repeat("[.b]", 6) + "1", 20000},
+	}
+
+	for _, c := range cases {
+		_, plain, err := version.Regexp(c.Layout)
+		if err != nil {
+			t.Errorf("unexpected error: %v; layout: %v", err, c.Layout)
+			continue
+		}
+		if len(plain) > c.Expected {
+			t.Errorf("length expectation failed, expected: <= %v, actual: %v; layout: %v", c.Expected, len(plain), c.Layout)
+		}
+	}
+
+	layout := "5" + strings.Repeat("[.1", 500) + strings.Repeat("]", 500)
+	if _, _, err := version.Regexp(layout); err == nil {
+		t.Errorf("error expected for an expression too large")
+	}
+	for _, layout := range []string{"YYYY.0M.0D.3", "YYYY.WW(.0M|.5).0D"} {
+		if _, _, err := version.Regexp(layout); err == nil {
+			t.Errorf("error expected for a date field read more than once; layout: %v", layout)
+		}
+	}
+}
+
+// TestRegexpParse checks the expression accepts exactly the version strings Parse accepts,
+// on strings written from generated versions, mutated, or made of pieces of version strings.
+func TestRegexpParse(t *testing.T) {
+	layouts := []string{
+		"5.4.3",
+		"[v]5$.4$.3[-beta[.1]]['+'o]",
+		"5.4.3-b1",
+		"5.4.3[-b]1",
+		"5.4[.3][-beta-][.1]",
+		"5.4.3(-b|.b)1",
+		"5.4.3(-b|.b|!'rc')[.1]",
+		"5.4.3[-Beta][1]",
+		"5.4[.3]o",
+		"5.4[.3]-o",
+		"5.4z",
+		"5.4y[.z]",
+		"5.4.3.1",
+		"05.004.3",
+		"5[.4]3",
+		"v5.4",
+		"!v5.4",
+		"5.4.3'rc'1",
+		"5.4.3[!'-rc'1]",
+		"5$.4$.3$-b$.1",
+		"YYYY.0M[.1]",
+		"0Y.MM$.1",
+		"YYYY.0M.0D",
+		"YYYY.0M[.0D]$.1",
+		"0D.0M.YY",
+		"MM-DD",
+		"YYYY.WW",
+		"0Y.0W[.1]",
+		"(YYYY.0M.0D|YYYY.WW)",
+		"v{major}.{minor}[.{patch}]{-pre}{.build}",
+		"{major}.{minor}{pre:b}{build}{+other}",
+		"5[.1][.1][.1]",
+		"5.4.3[-b][-b]1",
+		"5.4[.3][-b][.1]",
+		"5.4[.3][.3][-beta-][.b][.1]",
+	}
+	pieces := []string{
+		"0", "1", "2", "9", "12", "007", "53", "9223372036854775807", "9223372036854775808", "99999999999999999999",
+		".", "-", "+", "v", "a", "b", "z", "x", "B", "A", "RC", "rc", "r", "alpha", "beta", "Beta", "final", "'",
+		"02", "29", "30", "31", "04", "2000", "1900", "2020", "2021", "2024", "24", "100", "1.02.29", "2023.02.", "2024.02.", "9223372036854775804",
+	}
+	rnd := rand.New(rand.NewSource(1))
+
+	for _, layoutString := range layouts {
+		layout := version.MustCompile(layoutString)
+		r, plain, err := version.Regexp(layoutString)
+		if err != nil {
+			t.Errorf("unexpected error: %v; layout: %v", err, layoutString)
+			continue
+		}
+		if len(plain) == 0 {
+			t.Errorf("plain expression expected; layout: %v", layoutString)
+		}
+
+		inputs := make([]string, 0)
+		for i := 0; i < 200; i++ {
+			s := layout.Format(layout.Generate(rnd, 12))
+			inputs = append(inputs, s, mutate(rnd, s, pieces))
+		}
+		for i := 0; i < 1000; i++ {
+			var b strings.Builder
+			for n := rnd.Intn(8); n >= 0; n-- {
+				b.WriteString(pieces[rnd.Intn(len(pieces))])
+			}
+			inputs = append(inputs, b.String())
+		}
+
+		for _, s := range inputs {
+			_, err := layout.Parse(s)
+			if matched := r.MatchString(s); matched != (err == nil) {
+				t.Errorf("match expectation failed, expected: %v, actual: %v; layout: %v, input: %q, error: %v", err == nil, matched, layoutString, s, err)
+			}
+		}
+	}
+}
+
+// mutate returns a string with a piece inserted, or a byte deleted or replaced.
+func mutate(rnd *rand.Rand, s string, pieces []string) string {
+	i := rnd.Intn(len(s) + 1)
+	piece := pieces[rnd.Intn(len(pieces))]
+	switch {
+	case rnd.Intn(3) == 0 || i == len(s):
+		return s[:i] + piece + s[i:]
+	case rnd.Intn(2) == 0:
+		return s[:i] + s[i+1:]
+	default:
+		return s[:i] + piece + s[i+1:]
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explain explains the derivation of an incompatibility in lines,
+// where a line concluding an incompatibility referred more than once is numbered, e.g.
+//
+//	Because foo 1.0.0 depends on bar >=2 and no versions of bar match >=2, foo 1.0.0 is forbidden.
+//	And because root 1.0.0 depends on foo >=1, version solving failed.
+func Explain(inc *Incompatibility) string {
+	if !inc.IsDerived() {
+		return "Because " + inc.String() + ", version solving failed."
+	}
+	e := &explainer{refs: make(map[*Incompatibility]int), numbers: make(map[*Incompatibility]int)}
+	e.count(inc)
+	e.visit(inc, true)
+	return strings.Join(e.lines, "\n")
+}
+
+type explainer struct {
+	lines   []string
+	refs    map[*Incompatibility]int // how many times a derived incompatibility is referred
+	numbers map[*Incompatibility]int // the line numbers of incompatibilities numbered
+}
+
+func (e *explainer) count(inc *Incompatibility) {
+	for _, cause := range inc.causes {
+		if cause.IsDerived() {
+			e.refs[cause]++
+			if e.refs[cause] == 1 {
+				e.count(cause)
+			}
+		}
+	}
+	if inc.causes[0].IsDerived() && inc.causes[1].IsDerived() {
+		// the line of the first cause is not followed by the line referring to it, so it is numbered
+		e.refs[inc.causes[0]]++
+	}
+}
+
+// ref returns the incompatibility with its line number, if it is numbered.
+func (e *explainer) ref(inc *Incompatibility) string {
+	if n, ok := e.numbers[inc]; ok {
+		return fmt.Sprintf("%v (%d)", inc, n)
+	}
+	return inc.String()
+}
+
+// write writes a line concluding the incompatibility, numbered if it is referred more than once.
+func (e *explainer) write(inc *Incompatibility, line string) {
+	if e.refs[inc] > 1 {
+		n := len(e.numbers) + 1
+		e.numbers[inc] = n
+		line = fmt.Sprintf("%s (%d)", line, n)
+	}
+	e.lines = append(e.lines, line)
+}
+
+// visit writes the lines concluding the incompatibility, where the last line concludes the failure.
+func (e *explainer) visit(inc *Incompatibility, last bool) {
+	conclusion := inc.String()
+	if last {
+		conclusion = "version solving failed"
+	}
+	cause1, cause2 := inc.causes[0], inc.causes[1]
+	_, numbered1 := e.numbers[cause1]
+	_, numbered2 := e.numbers[cause2]
+
+	switch {
+	case cause1.IsDerived() && cause2.IsDerived():
+		if !numbered1 {
+			e.visit(cause1, false)
+		}
+		if !numbered2 {
+			e.visit(cause2, false)
+		}
+		e.write(inc, fmt.Sprintf("Because %s and %s, %s.", e.ref(cause1), e.ref(cause2), conclusion))
+	case cause1.IsDerived() || cause2.IsDerived():
+		derived, external, numbered := cause1, cause2, numbered1
+		if !derived.IsDerived() {
+			derived, external, numbered = cause2, cause1, numbered2
+		}
+		if numbered {
+			e.write(inc, fmt.Sprintf("Because %s and %s, %s.", external, e.ref(derived), conclusion))
+		} else {
+			e.visit(derived, false)
+			e.write(inc, fmt.Sprintf("And because %s, %s.", external, conclusion))
+		}
+	default:
+		e.write(inc, fmt.Sprintf("Because %s and %s, %s.", cause1, cause2, conclusion))
+	}
+}
@@ -0,0 +1,507 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package resolver selects versions of packages satisfying their dependencies on each other,
+// with the PubGrub algorithm, which explains why when no selection exists.
+//
+// See https://github.com/dart-lang/pub/blob/master/doc/solver.md for the algorithm.
+package resolver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gsxab/go-version"
+)
+
+// Resolver selects versions of packages from a Source.
+type Resolver struct {
+	Source            Source
+	IncludePrerelease bool // whether pre-releases are selected
+}
+
+// Solution is the versions selected for the packages.
+type Solution map[string]*version.Version
+
+// NoSolutionError tells why no versions satisfy the dependencies, with the incompatibility concluded.
+type NoSolutionError struct {
+	Incompatibility *Incompatibility
+}
+
+// Error explains the derivation of the incompatibility, in lines.
+func (e *NoSolutionError) Error() string {
+	return Explain(e.Incompatibility)
+}
+
+// Resolve selects versions of the packages depended by the root package of the version,
+// like Resolver.Resolve without pre-releases.
+func Resolve(source Source, root string, v *version.Version) (Solution, error) {
+	r := &Resolver{Source: source}
+	return r.Resolve(root, v)
+}
+
+// Resolve selects versions of the packages depended by the root package of the version, including the root.
+// The greatest version of a package is tried first, and the packages with fewer versions left are decided first,
+// so the solution is the same for the same source.
+// A NoSolutionError is returned if no solution exists.
+func (r *Resolver) Resolve(root string, v *version.Version) (Solution, error) {
+	s := &solver{
+		Resolver: r,
+		root:     root,
+		versions: make(map[string][]*version.Version),
+		cache:    make(map[string][]Dependency),
+	}
+	return s.solve(v)
+}
+
+// assignment is a term in the partial solution, either a decision, or derived from an incompatibility.
+type assignment struct {
+	term     Term
+	level    int
+	decision bool
+	version  *version.Version // the version decided, if a decision
+	cause    *Incompatibility
+}
+
+type solver struct {
+	*Resolver
+	root              string
+	incompatibilities []*Incompatibility
+	assignments       []assignment
+	level             int
+	versions          map[string][]*version.Version // cached versions, from the greatest
+	cache             map[string][]Dependency       // cached dependencies, by the package and the sort key of the version
+}
+
+func (s *solver) solve(v *version.Version) (Solution, error) {
+	s.addIncompatibility(newIncompatibility([]Term{{s.root, version.NewConstraint(version.Point(v)), false}}, causeRoot))
+	next := s.root
+	for {
+		if err := s.propagate(next); err != nil {
+			return nil, err
+		}
+		pkg, err := s.decide()
+		if err != nil {
+			return nil, err
+		}
+		if pkg == "" {
+			break
+		}
+		next = pkg
+	}
+
+	solution := make(Solution)
+	for _, a := range s.assignments {
+		if a.decision {
+			solution[a.term.Package] = a.version
+		}
+	}
+	return solution, nil
+}
+
+func (s *solver) addIncompatibility(inc *Incompatibility) {
+	s.incompatibilities = append(s.incompatibilities, inc)
+}
+
+// relation tells how the partial solution relates to a term or an incompatibility.
+type relation int
+
+const (
+	satisfied relation = iota
+	contradicted
+	inconclusive
+)
+
+// termOf returns the intersection of the assignments of a package, among the first ones.
+func termOf(assignments []assignment, pkg string) (Term, bool) {
+	var result Term
+	found := false
+	for _, a := range assignments {
+		if a.term.Package != pkg {
+			continue
+		}
+		if found {
+			result = result.intersect(a.term)
+		} else {
+			result, found = a.term, true
+		}
+	}
+	return result, found
+}
+
+func (s *solver) relate(t Term) relation {
+	assigned, ok := termOf(s.assignments, t.Package)
+	switch {
+	case !ok:
+		return inconclusive
+	case assigned.satisfies(t):
+		return satisfied
+	case assigned.contradicts(t):
+		return contradicted
+	default:
+		return inconclusive
+	}
+}
+
+// propagate derives the terms implied by the incompatibilities, from the changes of a package.
+func (s *solver) propagate(pkg string) error {
+	changed := []string{pkg}
+	for len(changed) > 0 {
+		pkg, changed = changed[len(changed)-1], changed[:len(changed)-1]
+		for i := len(s.incompatibilities) - 1; i >= 0; i-- {
+			inc := s.incompatibilities[i]
+			if _, ok := inc.term(pkg); !ok {
+				continue
+			}
+			unsatisfied, rel := s.relateIncompatibility(inc)
+			switch rel {
+			case satisfied:
+				rootCause, err := s.resolveConflict(inc)
+				if err != nil {
+					return err
+				}
+				unsatisfied, _ = s.relateIncompatibility(rootCause)
+				s.derive(unsatisfied.negate(), rootCause)
+				changed = []string{unsatisfied.Package}
+				i = -1
+			case inconclusive:
+				s.derive(unsatisfied.negate(), inc)
+				changed = append(changed, unsatisfied.Package)
+			}
+		}
+	}
+	return nil
+}
+
+// relateIncompatibility returns satisfied if all terms are satisfied,
+// inconclusive with the term if all terms but it are satisfied, or contradicted otherwise,
+// where an incompatibility not known to be satisfied and with more than one term inconclusive is taken as contradicted,
+// since nothing is derived from it.
+func (s *solver) relateIncompatibility(inc *Incompatibility) (Term, relation) {
+	var unsatisfied *Term
+	for i, t := range inc.Terms {
+		switch s.relate(t) {
+		case contradicted:
+			return Term{}, contradicted
+		case inconclusive:
+			if unsatisfied != nil {
+				return Term{}, contradicted
+			}
+			unsatisfied = &inc.Terms[i]
+		}
+	}
+	if unsatisfied == nil {
+		return Term{}, satisfied
+	}
+	return *unsatisfied, inconclusive
+}
+
+func (s *solver) derive(t Term, cause *Incompatibility) {
+	s.assignments = append(s.assignments, assignment{term: t, level: s.level, cause: cause})
+}
+
+// satisfier returns the index of the earliest assignment, from which with all before the incompatibility is satisfied,
+// where the term of the package is intersected with the extra term first.
+func (s *solver) satisfier(inc *Incompatibility, before int, extra *Term) int {
+	result := -1
+	for _, t := range inc.Terms {
+		var accumulated Term
+		found := false
+		if extra != nil && extra.Package == t.Package {
+			accumulated, found = *extra, true
+		}
+		index := -1
+		if found && accumulated.satisfies(t) {
+			index = -1
+		} else {
+			for i := 0; i < before; i++ {
+				a := s.assignments[i]
+				if a.term.Package != t.Package {
+					continue
+				}
+				if found {
+					accumulated = accumulated.intersect(a.term)
+				} else {
+					accumulated, found = a.term, true
+				}
+				if accumulated.satisfies(t) {
+					index = i
+					break
+				}
+			}
+		}
+		if index > result {
+			result = index
+		}
+	}
+	return result
+}
+
+// resolveConflict concludes the root cause of a satisfied incompatibility, and backtracks to where it is not satisfied,
+// or returns a NoSolutionError if the root package is concluded impossible.
+func (s *solver) resolveConflict(inc *Incompatibility) (*Incompatibility, error) {
+	original := inc
+	for {
+		if len(inc.Terms) == 0 || len(inc.Terms) == 1 && inc.Terms[0].Positive && inc.Terms[0].Package == s.root {
+			return nil, &NoSolutionError{Incompatibility: inc}
+		}
+
+		index := s.satisfier(inc, len(s.assignments), nil)
+		satisfier := s.assignments[index]
+		term, _ := inc.term(satisfier.term.Package)
+		previousIndex := s.satisfier(inc, index, &satisfier.term)
+		previousLevel := 1
+		if previousIndex >= 0 && s.assignments[previousIndex].level > previousLevel {
+			previousLevel = s.assignments[previousIndex].level
+		}
+
+		if satisfier.decision || previousLevel != satisfier.level {
+			if inc != original {
+				s.addIncompatibility(inc)
+			}
+			s.backtrack(previousLevel)
+			return inc, nil
+		}
+
+		terms := make([]Term, 0, len(inc.Terms)+len(satisfier.cause.Terms))
+		for _, t := range inc.Terms {
+			if t.Package != satisfier.term.Package {
+				terms = append(terms, t)
+			}
+		}
+		for _, t := range satisfier.cause.Terms {
+			if t.Package != satisfier.term.Package {
+				terms = append(terms, t)
+			}
+		}
+		if !satisfier.term.satisfies(term) {
+			terms = append(terms, satisfier.term.intersect(term.negate()).negate())
+		}
+		inc = s.derivedIncompatibility(terms, inc, satisfier.cause)
+	}
+}
+
+// derivedIncompatibility returns an incompatibility concluded from two others,
+// where a positive term of the root is dropped, since it always holds.
+func (s *solver) derivedIncompatibility(terms []Term, cause1 *Incompatibility, cause2 *Incompatibility) *Incompatibility {
+	if len(terms) > 1 {
+		withoutRoot := make([]Term, 0, len(terms))
+		for _, t := range terms {
+			if !t.Positive || t.Package != s.root {
+				withoutRoot = append(withoutRoot, t)
+			}
+		}
+		terms = withoutRoot
+	}
+	return newIncompatibility(terms, causeDerived, cause1, cause2)
+}
+
+func (s *solver) backtrack(level int) {
+	kept := s.assignments[:0]
+	for _, a := range s.assignments {
+		if a.level <= level {
+			kept = append(kept, a)
+		}
+	}
+	s.assignments = kept
+	s.level = level
+}
+
+// available returns the versions of a package which may be selected, from the greatest.
+func (s *solver) available(pkg string) ([]*version.Version, error) {
+	if versions, ok := s.versions[pkg]; ok {
+		return versions, nil
+	}
+	all, err := s.Source.Versions(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("versions of package %s: %w", pkg, err)
+	}
+	versions := make([]*version.Version, 0, len(all))
+	for _, v := range all {
+		if v.PreRel == version.Release || s.IncludePrerelease {
+			versions = append(versions, v)
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[j].LT(versions[i])
+	})
+	s.versions[pkg] = versions
+	return versions, nil
+}
+
+// allowed returns the versions of a package satisfying the constraint, from the greatest.
+func (s *solver) allowed(pkg string, constraint *version.Constraint) ([]*version.Version, error) {
+	versions, err := s.available(pkg)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*version.Version, 0, len(versions))
+	for _, v := range versions {
+		if constraint.Check(v) {
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+// dependencies returns the dependencies of a version of a package, cached.
+func (s *solver) dependencies(pkg string, v *version.Version) ([]Dependency, error) {
+	key := pkg + "@" + version.SortKeyString(v)
+	if dependencies, ok := s.cache[key]; ok {
+		return dependencies, nil
+	}
+	dependencies, err := s.Source.Dependencies(pkg, v)
+	if err != nil {
+		return nil, fmt.Errorf("dependencies of package %s: %w", pkg, err)
+	}
+	s.cache[key] = dependencies
+	return dependencies, nil
+}
+
+// hasDependency reports whether a version of a package has the same dependency.
+func (s *solver) hasDependency(pkg string, v *version.Version, d Dependency) (bool, error) {
+	dependencies, err := s.dependencies(pkg, v)
+	if err != nil {
+		return false, err
+	}
+	for _, d2 := range dependencies {
+		if d2.Package == d.Package && d2.Constraint.Equal(d.Constraint) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// dependencyRange returns the versions of a package next to the version with the same dependency,
+// so that the incompatibility of the dependency covers them all, e.g. `foo >=1.0.0, <1.2.0 depends on bar ^2`.
+func (s *solver) dependencyRange(pkg string, v *version.Version, d Dependency) (*version.Constraint, error) {
+	if pkg == s.root {
+		return version.NewConstraint(version.Point(v)), nil
+	}
+	versions, err := s.available(pkg)
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	for i, v2 := range versions {
+		if v2.EQ(v) {
+			index = i
+		}
+	}
+	if index == -1 {
+		return version.NewConstraint(version.Point(v)), nil
+	}
+	top, bottom := index, index
+	for ; top > 0; top-- {
+		if ok, err := s.hasDependency(pkg, versions[top-1], d); err != nil {
+			return nil, err
+		} else if !ok {
+			break
+		}
+	}
+	for ; bottom < len(versions)-1; bottom++ {
+		if ok, err := s.hasDependency(pkg, versions[bottom+1], d); err != nil {
+			return nil, err
+		} else if !ok {
+			break
+		}
+	}
+	interval := version.Interval{}
+	if top > 0 {
+		interval.Upper = versions[top-1]
+	}
+	if bottom < len(versions)-1 {
+		interval.Lower = versions[bottom]
+	}
+	return version.NewConstraint(interval), nil
+}
+
+// othersSatisfied reports whether the terms of an incompatibility not of the package are all satisfied.
+func (s *solver) othersSatisfied(inc *Incompatibility, pkg string) bool {
+	for _, t := range inc.Terms {
+		if t.Package != pkg && s.relate(t) != satisfied {
+			return false
+		}
+	}
+	return true
+}
+
+// decide selects a version of a package required but not decided, and returns the package,
+// or an empty string if all packages required are decided.
+func (s *solver) decide() (string, error) {
+	seen := make(map[string]bool) // decided or tried
+	for _, a := range s.assignments {
+		if a.decision {
+			seen[a.term.Package] = true
+		}
+	}
+	pkg := ""
+	var term Term
+	var candidates []*version.Version
+	for _, a := range s.assignments {
+		p := a.term.Package
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		t, _ := termOf(s.assignments, p)
+		if !t.Positive {
+			continue
+		}
+		allowed, err := s.allowed(p, t.Constraint)
+		if err != nil {
+			return "", err
+		}
+		if pkg == "" || len(allowed) < len(candidates) || len(allowed) == len(candidates) && p < pkg {
+			pkg, term, candidates = p, t, allowed
+		}
+	}
+	if pkg == "" {
+		return "", nil
+	}
+
+	if len(candidates) == 0 {
+		s.addIncompatibility(newIncompatibility([]Term{term}, causeNoVersions))
+		return pkg, nil
+	}
+	v := candidates[0]
+	dependencies, err := s.dependencies(pkg, v)
+	if err != nil {
+		return "", err
+	}
+	conflict := false
+	for _, d := range dependencies {
+		if d.Package == pkg && d.Constraint.Check(v) {
+			// a dependency on itself is satisfied by itself, or the version is forbidden by the incompatibility
+			continue
+		}
+		versions, err := s.dependencyRange(pkg, v, d)
+		if err != nil {
+			return "", err
+		}
+		inc := newIncompatibility([]Term{{pkg, versions, true}, {d.Package, d.Constraint, false}}, causeDependency)
+		s.addIncompatibility(inc)
+		conflict = conflict || s.othersSatisfied(inc, pkg)
+	}
+	if !conflict {
+		s.level++
+		self := Term{pkg, version.NewConstraint(version.Point(v)), true}
+		s.assignments = append(s.assignments, assignment{term: self, level: s.level, decision: true, version: v})
+	}
+	return pkg, nil
+}
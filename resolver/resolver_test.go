@@ -0,0 +1,337 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolver_test
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gsxab/go-version"
+	"github.com/gsxab/go-version/resolver"
+)
+
+var layout = version.MustCompile("5$.4$.3[-beta.1]")
+var fullLayout = version.MustCompile("5.4.3[-beta.1]")
+
+// source returns a MemorySource of the releases, each like `foo 1.0.0` followed by dependencies like `bar ^1`.
+func source(t *testing.T, releases ...[]string) *resolver.MemorySource {
+	s := resolver.NewMemorySource()
+	for _, r := range releases {
+		fields := strings.Fields(r[0])
+		v, err := layout.Parse(fields[1])
+		if err != nil {
+			t.Fatalf("unexpected error: %v; release: %v", err, r)
+		}
+		dependencies := make([]resolver.Dependency, 0)
+		for _, d := range r[1:] {
+			i := strings.Index(d, " ")
+			c, err := layout.ParseConstraint(d[i+1:])
+			if err != nil {
+				t.Fatalf("unexpected error: %v; dependency: %v", err, d)
+			}
+			dependencies = append(dependencies, resolver.Dependency{Package: d[:i], Constraint: c})
+		}
+		s.Add(fields[0], v, dependencies...)
+	}
+	return s
+}
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Releases [][]string
+		Expected map[string]string
+	}{
+		{
+			"no conflicts",
+			[][]string{
+				{"root 1.0.0", "foo ^1", "bar ^1"},
+				{"foo 1.0.0", "baz ^1"},
+				{"foo 2.0.0", "baz ^1"},
+				{"bar 1.0.0", "baz ^1"},
+				{"baz 1.0.0"},
+				{"baz 1.1.0"},
+			},
+			map[string]string{"root": "1.0.0", "foo": "1.0.0", "bar": "1.0.0", "baz": "1.1.0"},
+		},
+		{
+			"avoiding conflict during decision making",
+			[][]string{
+				{"root 1.0.0", "foo ^1", "bar ^1"},
+				{"foo 1.1.0", "bar ^2"},
+				{"foo 1.0.0"},
+				{"bar 1.0.0"},
+				{"bar 1.1.0"},
+				{"bar 2.0.0"},
+			},
+			map[string]string{"root": "1.0.0", "foo": "1.0.0", "bar": "1.1.0"},
+		},
+		{
+			"performing conflict resolution",
+			[][]string{
+				{"root 1.0.0", "foo >=1"},
+				{"foo 2.0.0", "bar ^1"},
+				{"foo 1.0.0"},
+				{"bar 1.0.0", "foo ^1"},
+			},
+			map[string]string{"root": "1.0.0", "foo": "1.0.0"},
+		},
+		{
+			"conflict resolution with a partial satisfier",
+			[][]string{
+				{"root 1.0.0", "foo ^1", "target ^2"},
+				{"foo 1.1.0", "left ^1", "right ^1"},
+				{"foo 1.0.0"},
+				{"left 1.0.0", "shared >=1"},
+				{"right 1.0.0", "shared <2"},
+				{"shared 2.0.0"},
+				{"shared 1.0.0", "target ^1"},
+				{"target 2.0.0"},
+				{"target 1.0.0"},
+			},
+			map[string]string{"root": "1.0.0", "foo": "1.0.0", "target": "2.0.0"},
+		},
+		{
+			"pre-releases excluded",
+			[][]string{
+				{"root 1.0.0", "foo >=1"},
+				{"foo 1.0.0"},
+				{"foo 2.0.0-beta.1"},
+			},
+			map[string]string{"root": "1.0.0", "foo": "1.0.0"},
+		},
+	}
+
+	for _, c := range cases {
+		root, _ := layout.Parse("1.0.0")
+		solution, err := resolver.Resolve(source(t, c.Releases...), "root", root)
+		if err != nil {
+			t.Errorf("unexpected error: %v; case: %v", err, c.Name)
+			continue
+		}
+		if len(solution) != len(c.Expected) {
+			t.Errorf("solution expectation failed, expected: %v, actual: %v; case: %v", c.Expected, solution, c.Name)
+		}
+		for pkg, expected := range c.Expected {
+			if v, ok := solution[pkg]; !ok || fullLayout.Format(v) != expected {
+				t.Errorf("version of %v expectation failed, expected: %v, actual: %v; case: %v", pkg, expected, v, c.Name)
+			}
+		}
+	}
+}
+
+func TestResolveNoSolution(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Releases [][]string
+		Expected string
+	}{
+		{
+			"no versions",
+			[][]string{
+				{"root 1.0.0", "foo >=2"},
+				{"foo 1.0.0"},
+			},
+			"Because no versions of foo match >=2 and root 1.0.0 depends on foo >=2, version solving failed.",
+		},
+		{
+			"linear error reporting",
+			[][]string{
+				{"root 1.0.0", "foo ^1", "baz ^1"},
+				{"foo 1.0.0", "bar ^2"},
+				{"bar 2.0.0", "baz ^3"},
+				{"baz 1.0.0"},
+				{"baz 3.0.0"},
+			},
+			"Because every version of foo depends on bar ^2 and every version of bar depends on baz ^3, every version of foo requires baz ^3.\n" +
+				"And because root 1.0.0 depends on foo ^1, baz ^3 is required.\n" +
+				"And because root 1.0.0 depends on baz ^1, version solving failed.",
+		},
+		{
+			"branching error reporting",
+			[][]string{
+				{"root 1.0.0", "foo ^1"},
+				{"foo 1.0.0", "a ^1", "b ^1"},
+				{"foo 1.1.0", "x ^1", "y ^1"},
+				{"a 1.0.0", "b ^2"},
+				{"b 1.0.0"},
+				{"b 2.0.0"},
+				{"x 1.0.0", "y ^2"},
+				{"y 1.0.0"},
+				{"y 2.0.0"},
+			},
+			"Because every version of a depends on b ^2 and foo <1.1.0 depends on a ^1, foo <1.1.0 requires b ^2.\n" +
+				"And because foo <1.1.0 depends on b ^1, foo <1.1.0 is forbidden. (1)\n" +
+				"Because every version of x depends on y ^2 and foo >=1.1.0 depends on x ^1, foo >=1.1.0 requires y ^2.\n" +
+				"And because foo >=1.1.0 depends on y ^1, foo >=1.1.0 is forbidden.\n" +
+				"Because foo <1.1.0 is forbidden (1) and foo >=1.1.0 is forbidden, every version of foo is forbidden.\n" +
+				"And because root 1.0.0 depends on foo ^1, version solving failed.",
+		},
+	}
+
+	for _, c := range cases {
+		root, _ := layout.Parse("1.0.0")
+		_, err := resolver.Resolve(source(t, c.Releases...), "root", root)
+		var noSolution *resolver.NoSolutionError
+		if !errors.As(err, &noSolution) {
+			t.Errorf("error expectation failed, expected: %T, actual: %v; case: %v", noSolution, err, c.Name)
+			continue
+		}
+		if err.Error() != c.Expected {
+			t.Errorf("explanation expectation failed, expected:\n%v\nactual:\n%v\ncase: %v", c.Expected, err, c.Name)
+		}
+	}
+}
+
+func TestResolver(t *testing.T) {
+	s := source(t,
+		[]string{"root 1.0.0", "foo >=1"},
+		[]string{"foo 1.0.0"},
+		[]string{"foo 2.0.0-beta.1", "bar ^1"},
+		[]string{"foo 2.0.0-beta.1", "bar ^2"},
+	)
+	root, _ := layout.Parse("1.0.0")
+	r := &resolver.Resolver{Source: s, IncludePrerelease: true}
+	solution, err := r.Resolve("root", root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fullLayout.Format(solution["foo"]) != "1.0.0" {
+		t.Errorf("version expectation failed, expected: 1.0.0, actual: %v", solution["foo"])
+	}
+
+	s.Add("bar", mustParse(t, "2.0.0"))
+	solution, err = r.Resolve("root", root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fullLayout.Format(solution["foo"]) != "2.0.0-beta.1" || fullLayout.Format(solution["bar"]) != "2.0.0" {
+		t.Errorf("solution expectation failed, actual: %v", solution)
+	}
+	if packages := s.Packages(); strings.Join(packages, " ") != "bar foo root" {
+		t.Errorf("packages expectation failed, actual: %v", packages)
+	}
+
+	if _, err := resolver.Resolve(s, "missing", root); err == nil {
+		t.Errorf("missing root accepted")
+	}
+	if _, err := resolver.Resolve(failingSource{}, "root", root); !errors.Is(err, errFailing) {
+		t.Errorf("error expectation failed, expected: %v, actual: %v", errFailing, err)
+	}
+}
+
+var errFailing = errors.New("source failing")
+
+type failingSource struct{}
+
+func (failingSource) Versions(pkg string) ([]*version.Version, error) {
+	return nil, errFailing
+}
+
+func (failingSource) Dependencies(pkg string, v *version.Version) ([]resolver.Dependency, error) {
+	return nil, errFailing
+}
+
+func mustParse(t *testing.T, versionString string) *version.Version {
+	v, err := layout.Parse(versionString)
+	if err != nil {
+		t.Fatalf("unexpected error: %v; version: %v", err, versionString)
+	}
+	return v
+}
+
+// TestResolveRandom checks solutions of random sources against all selections of versions.
+func TestResolveRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	packages := []string{"a", "b", "c", "d"}
+	for n := 0; n < 300; n++ {
+		s := resolver.NewMemorySource()
+		releases := make(map[string][]*version.Version)
+		deps := func() []resolver.Dependency {
+			dependencies := make([]resolver.Dependency, 0)
+			for _, pkg := range packages {
+				if r.Intn(3) == 0 {
+					lower := &version.Version{Major: int64(r.Intn(3))}
+					upper := &version.Version{Major: lower.Major + 1 + int64(r.Intn(2))}
+					c := version.NewConstraint(version.Interval{Lower: lower, Upper: upper})
+					dependencies = append(dependencies, resolver.Dependency{Package: pkg, Constraint: c})
+				}
+			}
+			return dependencies
+		}
+		s.Add("root", &version.Version{Major: 1}, deps()...)
+		for _, pkg := range packages {
+			for major := 0; major < 4; major++ {
+				if r.Intn(2) == 0 {
+					v := &version.Version{Major: int64(major)}
+					s.Add(pkg, v, deps()...)
+					releases[pkg] = append(releases[pkg], v)
+				}
+			}
+		}
+
+		solution, err := resolver.Resolve(s, "root", &version.Version{Major: 1})
+		var noSolution *resolver.NoSolutionError
+		if err != nil && !errors.As(err, &noSolution) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err == nil && !valid(s, solution) {
+			t.Errorf("invalid solution: %v", solution)
+		}
+		if err != nil && exists(s, releases, packages, resolver.Solution{"root": {Major: 1}}) {
+			t.Errorf("solution exists but not found: %v", err)
+		}
+	}
+}
+
+func valid(s *resolver.MemorySource, solution resolver.Solution) bool {
+	for pkg, v := range solution {
+		dependencies, err := s.Dependencies(pkg, v)
+		if err != nil {
+			return false
+		}
+		for _, d := range dependencies {
+			if selected, ok := solution[d.Package]; !ok || !d.Constraint.Check(selected) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func exists(s *resolver.MemorySource, releases map[string][]*version.Version, packages []string, solution resolver.Solution) bool {
+	if len(packages) == 0 {
+		return valid(s, solution)
+	}
+	pkg := packages[0]
+	if exists(s, releases, packages[1:], solution) {
+		return true
+	}
+	for _, v := range releases[pkg] {
+		solution[pkg] = v
+		if exists(s, releases, packages[1:], solution) {
+			delete(solution, pkg)
+			return true
+		}
+	}
+	delete(solution, pkg)
+	return false
+}
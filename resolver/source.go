@@ -0,0 +1,99 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gsxab/go-version"
+)
+
+// Dependency is a constraint of a package on another package.
+type Dependency struct {
+	Package    string
+	Constraint *version.Constraint
+}
+
+// Source provides the versions of packages and their dependencies.
+type Source interface {
+	// Versions returns the versions of a package, in any order.
+	Versions(pkg string) ([]*version.Version, error)
+	// Dependencies returns the dependencies of a version of a package.
+	Dependencies(pkg string, v *version.Version) ([]Dependency, error)
+}
+
+// MemorySource is a Source of packages added in memory.
+type MemorySource struct {
+	packages map[string][]release
+}
+
+type release struct {
+	version      *version.Version
+	dependencies []Dependency
+}
+
+// NewMemorySource returns an empty MemorySource.
+func NewMemorySource() *MemorySource {
+	return &MemorySource{packages: make(map[string][]release)}
+}
+
+// Add adds a version of a package with its dependencies, replacing the same version added before.
+func (s *MemorySource) Add(pkg string, v *version.Version, dependencies ...Dependency) {
+	releases := s.packages[pkg]
+	for i, r := range releases {
+		if r.version.EQ(v) {
+			releases[i].dependencies = dependencies
+			return
+		}
+	}
+	s.packages[pkg] = append(releases, release{version: v, dependencies: dependencies})
+}
+
+// Packages returns the names of the packages added, in order.
+func (s *MemorySource) Packages() []string {
+	packages := make([]string, 0, len(s.packages))
+	for pkg := range s.packages {
+		packages = append(packages, pkg)
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// Versions returns the versions of a package added, in the order they are added.
+// A package never added has no versions.
+func (s *MemorySource) Versions(pkg string) ([]*version.Version, error) {
+	releases := s.packages[pkg]
+	versions := make([]*version.Version, len(releases))
+	for i, r := range releases {
+		versions[i] = r.version
+	}
+	return versions, nil
+}
+
+// Dependencies returns the dependencies of a version of a package added,
+// or an error if the version is not added.
+func (s *MemorySource) Dependencies(pkg string, v *version.Version) ([]Dependency, error) {
+	for _, r := range s.packages[pkg] {
+		if r.version.EQ(v) {
+			return r.dependencies, nil
+		}
+	}
+	return nil, fmt.Errorf("package %s of version %+v not found", pkg, *v)
+}
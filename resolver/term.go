@@ -0,0 +1,185 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resolver
+
+import (
+	"strings"
+
+	"github.com/gsxab/go-version"
+)
+
+// Term is a statement about a package, that it is selected with a version satisfying the constraint if positive,
+// or that it is not selected with such a version if negative.
+type Term struct {
+	Package    string
+	Constraint *version.Constraint
+	Positive   bool
+}
+
+func (t Term) negate() Term {
+	return Term{Package: t.Package, Constraint: t.Constraint, Positive: !t.Positive}
+}
+
+// intersect returns the term holding if both terms of the same package hold.
+func (t Term) intersect(t2 Term) Term {
+	switch {
+	case t.Positive && t2.Positive:
+		return Term{t.Package, t.Constraint.Intersect(t2.Constraint), true}
+	case t.Positive:
+		return Term{t.Package, t.Constraint.Difference(t2.Constraint), true}
+	case t2.Positive:
+		return Term{t.Package, t2.Constraint.Difference(t.Constraint), true}
+	default:
+		return Term{t.Package, t.Constraint.Union(t2.Constraint), false}
+	}
+}
+
+// satisfies reports whether the term implies another term of the same package.
+func (t Term) satisfies(t2 Term) bool {
+	switch {
+	case t.Positive && t2.Positive:
+		return t2.Constraint.Allows(t.Constraint)
+	case t.Positive:
+		return t.Constraint.Intersect(t2.Constraint).IsEmpty()
+	case t2.Positive:
+		return false
+	default:
+		return t.Constraint.Allows(t2.Constraint)
+	}
+}
+
+// contradicts reports whether the term and another term of the same package cannot both hold.
+func (t Term) contradicts(t2 Term) bool {
+	switch {
+	case t.Positive && t2.Positive:
+		return t.Constraint.Intersect(t2.Constraint).IsEmpty()
+	case t.Positive:
+		return t2.Constraint.Allows(t.Constraint)
+	case t2.Positive:
+		return t.Constraint.Allows(t2.Constraint)
+	default:
+		return false
+	}
+}
+
+// String returns the term as a package and a constraint, e.g. `foo >=1.2`, with `not` before a negative term.
+func (t Term) String() string {
+	if t.Positive {
+		return t.describe()
+	}
+	return "not " + t.describe()
+}
+
+// describe returns the package and the constraint of the term, regardless of the sign,
+// e.g. `every version of foo` for a positive term of all versions, and `foo` for a negative one.
+func (t Term) describe() string {
+	if t.Constraint.IsAny() && t.Positive {
+		return "every version of " + t.Package
+	} else if t.Constraint.IsAny() {
+		return t.Package
+	}
+	return t.Package + " " + t.Constraint.String()
+}
+
+type causeKind int
+
+const (
+	causeRoot causeKind = iota
+	causeDependency
+	causeNoVersions
+	causeDerived
+)
+
+// Incompatibility is a set of terms which cannot all hold, with the cause it is known from.
+// A derived incompatibility is concluded from two other incompatibilities, which are its causes.
+type Incompatibility struct {
+	Terms  []Term
+	kind   causeKind
+	causes [2]*Incompatibility
+}
+
+// newIncompatibility returns an incompatibility of the terms, where the terms of the same package are merged.
+func newIncompatibility(terms []Term, kind causeKind, causes ...*Incompatibility) *Incompatibility {
+	merged := make([]Term, 0, len(terms))
+	index := make(map[string]int)
+	for _, t := range terms {
+		if i, ok := index[t.Package]; ok {
+			merged[i] = merged[i].intersect(t)
+			continue
+		}
+		index[t.Package] = len(merged)
+		merged = append(merged, t)
+	}
+	inc := &Incompatibility{Terms: merged, kind: kind}
+	copy(inc.causes[:], causes)
+	return inc
+}
+
+// IsDerived reports whether the incompatibility is concluded from two others.
+func (inc *Incompatibility) IsDerived() bool {
+	return inc.kind == causeDerived
+}
+
+// Causes returns the two incompatibilities a derived incompatibility is concluded from, or nil otherwise.
+func (inc *Incompatibility) Causes() (*Incompatibility, *Incompatibility) {
+	return inc.causes[0], inc.causes[1]
+}
+
+func (inc *Incompatibility) term(pkg string) (Term, bool) {
+	for _, t := range inc.Terms {
+		if t.Package == pkg {
+			return t, true
+		}
+	}
+	return Term{}, false
+}
+
+// String explains the incompatibility in a sentence without the cause, e.g. `foo 1.0.0 depends on bar >=2`.
+func (inc *Incompatibility) String() string {
+	switch {
+	case inc.kind == causeRoot:
+		return inc.Terms[0].describe() + " is required"
+	case inc.kind == causeDependency && len(inc.Terms) == 2: // not a dependency on itself
+		return inc.Terms[0].describe() + " depends on " + inc.Terms[1].describe()
+	case inc.kind == causeNoVersions:
+		return "no versions of " + inc.Terms[0].Package + " match " + inc.Terms[0].Constraint.String()
+	}
+
+	positives := make([]string, 0, len(inc.Terms))
+	negatives := make([]string, 0, len(inc.Terms))
+	for _, t := range inc.Terms {
+		if t.Positive {
+			positives = append(positives, t.describe())
+		} else {
+			negatives = append(negatives, t.describe())
+		}
+	}
+	switch {
+	case len(inc.Terms) == 0:
+		return "version solving failed"
+	case len(negatives) == 0 && len(positives) == 1:
+		return positives[0] + " is forbidden"
+	case len(negatives) == 0:
+		return strings.Join(positives, " and ") + " are incompatible"
+	case len(positives) == 0:
+		return strings.Join(negatives, " or ") + " is required"
+	default:
+		return strings.Join(positives, " and ") + " requires " + strings.Join(negatives, " or ")
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// RoundTripError tells why a version string is not written back canonically.
+type RoundTripError struct {
+	Layout    string
+	Input     string
+	Canonical string // the string written from the version read from the input
+	Reason    string
+}
+
+func (e *RoundTripError) Error() string {
+	return fmt.Sprintf("version string %s written back as %s in layout %s: %s", e.Input, e.Canonical, e.Layout, e.Reason)
+}
+
+// CheckRoundTrip checks that a version is read back the same from the string written in the layout,
+// and returns a FormatError otherwise.
+func CheckRoundTrip(layout string, v *Version) error {
+	_, err := FormatStrict(layout, v)
+	return err
+}
+
+// CheckRoundTripString checks that a version string is read in the layout,
+// and the version is written as a canonical string, which is read back as the same version and written the same.
+// The canonical string is not necessarily the input, e.g. `1.2` for `v1.2.0` in `[v]5.4$.3`.
+func CheckRoundTripString(layout string, versionString string) error {
+	l, err := Compile(layout)
+	if err != nil {
+		return err
+	}
+	return l.CheckRoundTripString(versionString)
+}
+
+// CheckRoundTrip checks that a version is read back the same, like the function CheckRoundTrip.
+func (l *Layout) CheckRoundTrip(v *Version) error {
+	_, err := l.FormatStrict(v)
+	return err
+}
+
+// CheckRoundTripString checks that a version string is written back canonically, like the function CheckRoundTripString.
+func (l *Layout) CheckRoundTripString(versionString string) error {
+	v, err := l.Parse(versionString)
+	if err != nil {
+		return err
+	}
+	canonical := l.Format(v)
+	fail := func(reason string) error {
+		return &RoundTripError{Layout: l.source, Input: versionString, Canonical: canonical, Reason: reason}
+	}
+	v2, err := l.Parse(canonical)
+	if err != nil {
+		return fail(fmt.Sprintf("not readable: %v", err))
+	}
+	if dropped := droppedFields(v, v2); len(dropped) > 0 {
+		field := dropped[0].Field
+		return fail(fmt.Sprintf("%v %s read back as %s", field, dropped[0].Value, field.valueString(v2)))
+	}
+	if again := l.Format(v2); again != canonical {
+		return fail(fmt.Sprintf("written again as %s", again))
+	}
+	return nil
+}
+
+// Generate returns a random version representable in the layout, for property tests.
+// Counters are not greater than the size, groups and alternatives are chosen randomly,
+// and the other text, if any, is a plus sign followed by lowercase letters, e.g. `+abc`.
+func (l *Layout) Generate(r *rand.Rand, size int) *Version {
+	v := &Version{}
+	g := &generator{r: r, size: int64(size)}
+	g.generate(l.nodes, v)
+	return v
+}
+
+type generator struct {
+	r     *rand.Rand
+	size  int64
+	ended bool // whether an allowed end is taken
+}
+
+func (g *generator) generate(nodes []node, v *Version) {
+	for _, n := range nodes {
+		if g.ended {
+			return
+		}
+		switch n.field {
+		case build, patch, minor, major, alphabetic_build, alphabetic_patch:
+			n.field.SetField(v, g.r.Int63n(g.size+1))
+		case preRelTag:
+			v.PreRel = Alpha + PreRelTag(g.r.Intn(int(Release-Alpha)+1))
+		case year, short_year:
+			v.Major = 2000 + g.r.Int63n(100)
+		case month:
+			v.Minor = 1 + g.r.Int63n(12)
+		case week:
+			v.Minor = 1 + g.r.Int63n(52)
+		case day:
+			v.Patch = 1 + g.r.Int63n(28)
+		case other:
+			letters := make([]byte, g.r.Intn(4))
+			for i := range letters {
+				letters[i] = byte('a' + g.r.Intn(26))
+			}
+			if len(letters) > 0 {
+				v.Other = "+" + string(letters)
+			}
+		case allowEnd:
+			g.ended = g.r.Intn(2) == 0
+		case group:
+			if g.r.Intn(2) == 0 {
+				g.generate(n.alts[g.r.Intn(len(n.alts))], v)
+			}
+		case choice:
+			g.generate(n.alts[g.r.Intn(len(n.alts))], v)
+		}
+	}
+}
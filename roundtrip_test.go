@@ -0,0 +1,81 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestCheckRoundTrip(t *testing.T) {
+	cases := []struct {
+		Layout   string
+		Version  *version.Version
+		RaiseErr bool
+	}{
+		{"5.4$.3", &version.Version{Major: 1, Minor: 2}, false},
+		{"5.4y", &version.Version{Major: 1, Minor: 2, Patch: 26*26 + 1}, false},
+		{"5.4.3", &version.Version{Major: 1, Minor: 2, Patch: 3, Build: 4}, true},
+		{"5.4.3b1", &version.Version{Major: 1, Minor: 2, Patch: 3, Build: 4}, true},
+	}
+
+	for _, c := range cases {
+		err := version.CheckRoundTrip(c.Layout, c.Version)
+		if c.RaiseErr != (err != nil) {
+			t.Errorf("error expectation failed, expected: %v, actual: %v; layout: %v, input: %+v", c.RaiseErr, err, c.Layout, c.Version)
+		}
+	}
+}
+
+func TestCheckRoundTripString(t *testing.T) {
+	cases := []struct {
+		Layout        string
+		VersionString string
+		RaiseErr      bool
+	}{
+		{"[v]5.4$.3", "v1.2.0", false},
+		{"5.4.3z", "1.2.3zz", false},
+		{"5.4.3", "1.2.x", true},
+		// the alphabetic build `rc` is written as `1.2.3471` in the first alternative
+		{"5.4.3b1|5.4.3z", "1.2.3rc", true},
+	}
+
+	for _, c := range cases {
+		err := version.CheckRoundTripString(c.Layout, c.VersionString)
+		if c.RaiseErr != (err != nil) {
+			t.Errorf("error expectation failed, expected: %v, actual: %v; layout: %v, input: %v", c.RaiseErr, err, c.Layout, c.VersionString)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	l := version.MustCompile("YYYY.0M.0D[.1]")
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		v := l.Generate(r, 10)
+		if v.Major < 2000 || v.Minor < 1 || v.Minor > 12 || v.Patch < 1 || v.Patch > 28 || v.Build < 0 || v.Build > 10 {
+			t.Errorf("version not representable: %+v", v)
+		}
+		if err := l.CheckRoundTrip(v); err != nil {
+			t.Errorf("round trip failed: %v", err)
+		}
+	}
+}
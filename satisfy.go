@@ -0,0 +1,146 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoneSatisfying is returned when no candidate satisfies a constraint.
+var ErrNoneSatisfying = errors.New("no version satisfying the constraint")
+
+// SatisfyOptions are the options choosing candidates in MaxSatisfying, MinSatisfying and AllSatisfying.
+type SatisfyOptions struct {
+	IncludePrerelease bool                        // whether pre-releases are candidates
+	Yanked            func(candidate string) bool // whether a candidate is excluded, e.g. yanked from a registry
+}
+
+// InvalidCandidate is a candidate which cannot be read in the layout, and is skipped.
+type InvalidCandidate struct {
+	Candidate string
+	Err       error
+}
+
+func (c InvalidCandidate) String() string {
+	return fmt.Sprintf("%s: %v", c.Candidate, c.Err)
+}
+
+type candidate struct {
+	source  string
+	version *Version
+}
+
+// MaxSatisfying returns the greatest candidate satisfying the constraint, read in the layout,
+// or ErrNoneSatisfying if there is none.
+// A nil constraint holds for all versions, and nil options exclude pre-releases only.
+// Candidates which cannot be read are skipped and returned, rather than failing the query.
+func MaxSatisfying(layout string, candidates []string, constraint *Constraint, options *SatisfyOptions) (string, []InvalidCandidate, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return "", nil, err
+	}
+	return l.MaxSatisfying(candidates, constraint, options)
+}
+
+// MinSatisfying returns the least candidate satisfying the constraint, like MaxSatisfying.
+func MinSatisfying(layout string, candidates []string, constraint *Constraint, options *SatisfyOptions) (string, []InvalidCandidate, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return "", nil, err
+	}
+	return l.MinSatisfying(candidates, constraint, options)
+}
+
+// AllSatisfying returns all candidates satisfying the constraint, from the least version, like MaxSatisfying.
+// Candidates of the same version are kept in their order, and no satisfying candidate is not an error.
+func AllSatisfying(layout string, candidates []string, constraint *Constraint, options *SatisfyOptions) ([]string, []InvalidCandidate, error) {
+	l, err := Compile(layout)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l.AllSatisfying(candidates, constraint, options)
+}
+
+// MaxSatisfying returns the greatest candidate satisfying the constraint, like the function MaxSatisfying.
+func (l *Layout) MaxSatisfying(candidates []string, constraint *Constraint, options *SatisfyOptions) (string, []InvalidCandidate, error) {
+	satisfying, invalid := l.satisfying(candidates, constraint, options)
+	if len(satisfying) == 0 {
+		return "", invalid, ErrNoneSatisfying
+	}
+	best := satisfying[0]
+	for _, c := range satisfying[1:] {
+		if best.version.LT(c.version) {
+			best = c
+		}
+	}
+	return best.source, invalid, nil
+}
+
+// MinSatisfying returns the least candidate satisfying the constraint, like the function MinSatisfying.
+func (l *Layout) MinSatisfying(candidates []string, constraint *Constraint, options *SatisfyOptions) (string, []InvalidCandidate, error) {
+	satisfying, invalid := l.satisfying(candidates, constraint, options)
+	if len(satisfying) == 0 {
+		return "", invalid, ErrNoneSatisfying
+	}
+	best := satisfying[0]
+	for _, c := range satisfying[1:] {
+		if c.version.LT(best.version) {
+			best = c
+		}
+	}
+	return best.source, invalid, nil
+}
+
+// AllSatisfying returns all candidates satisfying the constraint, like the function AllSatisfying.
+// The error is always nil, since no satisfying candidate is not an error, and kept to match MaxSatisfying.
+func (l *Layout) AllSatisfying(candidates []string, constraint *Constraint, options *SatisfyOptions) ([]string, []InvalidCandidate, error) {
+	satisfying, invalid := l.satisfying(candidates, constraint, options)
+	sort.SliceStable(satisfying, func(i, j int) bool {
+		return satisfying[i].version.LT(satisfying[j].version)
+	})
+	all := make([]string, len(satisfying))
+	for i, c := range satisfying {
+		all[i] = c.source
+	}
+	return all, invalid, nil
+}
+
+func (l *Layout) satisfying(candidates []string, constraint *Constraint, options *SatisfyOptions) ([]candidate, []InvalidCandidate) {
+	if options == nil {
+		options = &SatisfyOptions{}
+	}
+	satisfying := make([]candidate, 0)
+	invalid := make([]InvalidCandidate, 0)
+	for _, source := range candidates {
+		v, err := l.Parse(source)
+		if err != nil {
+			invalid = append(invalid, InvalidCandidate{Candidate: source, Err: err})
+			continue
+		}
+		if v.PreRel != Release && !options.IncludePrerelease ||
+			options.Yanked != nil && options.Yanked(source) ||
+			constraint != nil && !constraint.Check(v) {
+			continue
+		}
+		satisfying = append(satisfying, candidate{source: source, version: v})
+	}
+	return satisfying, invalid
+}
@@ -0,0 +1,97 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestSatisfying(t *testing.T) {
+	const layout = "[v]5$.4$.3[-beta.1]"
+	candidates := []string{"v1.9.0", "v1.10.0", "v1.10.1-rc.1", "release-2", "v2.0.0", "v1.2", "1.10", "v1.11.0"}
+	yanked := func(candidate string) bool {
+		return candidate == "v1.11.0"
+	}
+	constraint, err := version.ParseConstraint(layout, ">=1.2, <2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		Options *version.SatisfyOptions
+		Max     string
+		Min     string
+		All     []string
+	}{
+		{nil, "v1.11.0", "v1.2", []string{"v1.2", "v1.9.0", "v1.10.0", "1.10", "v1.11.0"}},
+		{&version.SatisfyOptions{Yanked: yanked}, "v1.10.0", "v1.2", []string{"v1.2", "v1.9.0", "v1.10.0", "1.10"}},
+		{&version.SatisfyOptions{IncludePrerelease: true, Yanked: yanked}, "v1.10.1-rc.1", "v1.2", []string{"v1.2", "v1.9.0", "v1.10.0", "1.10", "v1.10.1-rc.1"}},
+	}
+
+	for _, c := range cases {
+		max, invalid, err := version.MaxSatisfying(layout, candidates, constraint, c.Options)
+		if err != nil || max != c.Max {
+			t.Errorf("max expectation failed, expected: %v, actual: %v, %v; options: %+v", c.Max, max, err, c.Options)
+		}
+		if len(invalid) != 1 || invalid[0].Candidate != "release-2" || invalid[0].Err == nil {
+			t.Errorf("invalid candidates expectation failed, actual: %v", invalid)
+		}
+		min, _, err := version.MinSatisfying(layout, candidates, constraint, c.Options)
+		if err != nil || min != c.Min {
+			t.Errorf("min expectation failed, expected: %v, actual: %v, %v; options: %+v", c.Min, min, err, c.Options)
+		}
+		all, _, err := version.AllSatisfying(layout, candidates, constraint, c.Options)
+		if err != nil || len(all) != len(c.All) {
+			t.Errorf("all expectation failed, expected: %v, actual: %v, %v; options: %+v", c.All, all, err, c.Options)
+			continue
+		}
+		for i := range all {
+			if all[i] != c.All[i] {
+				t.Errorf("all expectation failed, expected: %v, actual: %v; options: %+v", c.All, all, c.Options)
+				break
+			}
+		}
+	}
+
+	if max, _, err := version.MaxSatisfying(layout, candidates, nil, nil); err != nil || max != "v2.0.0" {
+		t.Errorf("max expectation failed, expected: v2.0.0, actual: %v, %v", max, err)
+	}
+	none, err := version.ParseConstraint(layout, ">=3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, err := version.MaxSatisfying(layout, candidates, none, nil); !errors.Is(err, version.ErrNoneSatisfying) {
+		t.Errorf("error expectation failed, expected: %v, actual: %v", version.ErrNoneSatisfying, err)
+	}
+	if _, _, err := version.MinSatisfying(layout, nil, nil, nil); !errors.Is(err, version.ErrNoneSatisfying) {
+		t.Errorf("error expectation failed, expected: %v, actual: %v", version.ErrNoneSatisfying, err)
+	}
+	if all, _, err := version.MustCompile(layout).AllSatisfying(candidates, none, nil); err != nil || len(all) != 0 {
+		t.Errorf("all expectation failed, expected none, actual: %v, %v", all, err)
+	}
+	if all, _, err := version.AllSatisfying(layout, candidates, none, nil); err != nil || len(all) != 0 {
+		t.Errorf("all expectation failed, expected none, actual: %v, %v", all, err)
+	}
+	if _, _, err := version.MaxSatisfying("5.4[", candidates, nil, nil); err == nil {
+		t.Errorf("ill-formed layout accepted")
+	}
+}
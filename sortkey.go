@@ -0,0 +1,69 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// SortKeySize is the length of a sort key in bytes.
+const SortKeySize = 5 * 8
+
+// sortKeyFields are the fields in a sort key, in the order of comparison.
+var sortKeyFields = []Field{major, minor, patch, preRelTag, build}
+
+// SortKey encodes a version as bytes, where the byte order is the same as the version order, i.e. LT and EQ.
+// Each field of the five is written as 8 bytes in big-endian with the sign bit flipped,
+// so pre-releases, whose tags are negative, sort below the release.
+// The other text is not encoded, because it is not compared.
+func SortKey(v *Version) []byte {
+	key := make([]byte, SortKeySize)
+	for i, field := range sortKeyFields {
+		binary.BigEndian.PutUint64(key[i*8:], uint64(field.value(v))^(1<<63))
+	}
+	return key
+}
+
+// SortKeyString encodes a version as a lowercase hex string of SortKey, which sorts the same as the bytes.
+func SortKeyString(v *Version) string {
+	return hex.EncodeToString(SortKey(v))
+}
+
+// DecodeSortKey decodes a version from a key returned by SortKey.
+func DecodeSortKey(key []byte) (*Version, error) {
+	if len(key) != SortKeySize {
+		return nil, fmt.Errorf("sort key of %d bytes, expected %d", len(key), SortKeySize)
+	}
+	v := &Version{}
+	for i, field := range sortKeyFields {
+		field.SetField(v, int64(binary.BigEndian.Uint64(key[i*8:])^(1<<63)))
+	}
+	return v, nil
+}
+
+// DecodeSortKeyString decodes a version from a string returned by SortKeyString.
+func DecodeSortKeyString(key string) (*Version, error) {
+	bytes, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("sort key %s not hex: %w", key, err)
+	}
+	return DecodeSortKey(bytes)
+}
@@ -0,0 +1,93 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package version_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gsxab/go-version"
+)
+
+func TestSortKey(t *testing.T) {
+	versions := []*version.Version{
+		{Major: math.MinInt64},
+		{Major: -1, Minor: 5},
+		{Major: 0},
+		{Major: 1, Minor: 9, PreRel: version.Alpha, Build: 1},
+		{Major: 1, Minor: 9, PreRel: version.Beta},
+		{Major: 1, Minor: 9, PreRel: version.ReleaseCandidate, Build: 2},
+		{Major: 1, Minor: 9},
+		{Major: 1, Minor: 9, Build: 1},
+		{Major: 1, Minor: 10},
+		{Major: 1, Minor: 10, Patch: 1},
+		{Major: math.MaxInt64},
+	}
+
+	for i, v1 := range versions {
+		for j, v2 := range versions {
+			k1, k2 := version.SortKey(v1), version.SortKey(v2)
+			expected := 0
+			if v1.LT(v2) {
+				expected = -1
+			} else if v2.LT(v1) {
+				expected = 1
+			}
+			if actual := bytes.Compare(k1, k2); actual != expected {
+				t.Errorf("byte order expectation failed, expected: %v, actual: %v; versions: %+v, %+v", expected, actual, v1, v2)
+			}
+			s1, s2 := version.SortKeyString(v1), version.SortKeyString(v2)
+			if actual := strings.Compare(s1, s2); actual != expected {
+				t.Errorf("string order expectation failed, expected: %v, actual: %v; versions: %+v, %+v", expected, actual, v1, v2)
+			}
+			if (i < j) != (expected < 0) {
+				t.Errorf("test case order is wrong: %+v, %+v", v1, v2)
+			}
+		}
+	}
+}
+
+func TestDecodeSortKey(t *testing.T) {
+	v := &version.Version{Major: 1, Minor: -2, Patch: 3, PreRel: version.Beta, Build: 4, Other: "ignored"}
+
+	v2, err := version.DecodeSortKey(version.SortKey(v))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !v2.EQ(v) || v2.Other != "" {
+		t.Errorf("version expectation failed, expected: %+v, actual: %+v", v, v2)
+	}
+
+	v2, err = version.DecodeSortKeyString(version.SortKeyString(v))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !v2.EQ(v) {
+		t.Errorf("version expectation failed, expected: %+v, actual: %+v", v, v2)
+	}
+
+	if _, err := version.DecodeSortKey([]byte{1, 2, 3}); err == nil {
+		t.Errorf("short sort key accepted")
+	}
+	if _, err := version.DecodeSortKeyString("xyz"); err == nil {
+		t.Errorf("ill-formed sort key string accepted")
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package updatecheck
+
+import (
+	"fmt"
+
+	"github.com/gsxab/go-version"
+)
+
+// Update is a newer release found for the version running.
+type Update struct {
+	Current *version.Version
+	// Latest is the newest release in the channel.
+	Latest *Release
+	// Next is the release to upgrade to, i.e. Latest, or the newest release upgrading from the version running
+	// if Latest does not, which is a step towards Latest.
+	Next *Release
+	// Mandatory tells whether a release after the version running and up to Latest is mandatory.
+	Mandatory bool
+}
+
+// NoUpgradePathError tells no release in the channel upgrades from the version running, though some are newer.
+type NoUpgradePathError struct {
+	Current string // the version running, written in the layout
+	Latest  string // the newest release, written in the layout
+}
+
+func (e *NoUpgradePathError) Error() string {
+	return fmt.Sprintf("no upgrade path from %s to %s", e.Current, e.Latest)
+}
+
+// Check returns the update for the version running, or nil if it is up to date.
+// It only considers the releases published to the channel, given as the least stable tag accepted,
+// e.g. Release for stable releases only, or Beta for betas, release candidates and stable releases.
+// It returns a NoUpgradePathError, where the versions are written in the layout, if no newer release upgrades from the version running.
+func (m *Manifest) Check(layout *version.Layout, current *version.Version, channel version.PreRelTag) (*Update, error) {
+	var update *Update
+	for i := range m.Releases {
+		release := &m.Releases[i]
+		if release.Channel < channel || !current.LT(release.Version) {
+			continue
+		}
+		if update == nil {
+			update = &Update{Current: current}
+		}
+		if update.Latest == nil || update.Latest.Version.LT(release.Version) {
+			update.Latest = release
+		}
+		if release.Mandatory {
+			update.Mandatory = true
+		}
+		if release.MinimumUpgradeFrom != nil && current.LT(release.MinimumUpgradeFrom) {
+			continue
+		}
+		if update.Next == nil || update.Next.Version.LT(release.Version) {
+			update.Next = release
+		}
+	}
+	if update != nil && update.Next == nil {
+		return nil, &NoUpgradePathError{Current: layout.Format(current), Latest: layout.Format(update.Latest.Version)}
+	}
+	return update, nil
+}
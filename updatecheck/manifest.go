@@ -0,0 +1,157 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package updatecheck tells whether a newer version is released, from a release manifest in JSON, like
+//
+//	{"releases": [
+//		{"version": "1.4.0", "channel": "stable", "date": "2024-03-01", "minimum_upgrade_from": "1.2.0", "mandatory": true},
+//		{"version": "1.5.0-beta.1", "channel": "beta", "date": "2024-04-01"}
+//	]}
+//
+// where the versions are written in a layout.
+package updatecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gsxab/go-version"
+)
+
+// DateLayout is the layout of release dates in a manifest.
+const DateLayout = "2006-01-02"
+
+// Release is a release listed in a manifest.
+type Release struct {
+	Version *version.Version
+	// Channel is the least stable tag of the channels the release is published to,
+	// e.g. Release for stable, or Beta for beta, where releases are also published to less stable channels.
+	Channel version.PreRelTag
+	Date    time.Time // zero if not known
+	// MinimumUpgradeFrom is the least version upgrading to the release directly, or nil if any.
+	MinimumUpgradeFrom *version.Version
+	// Mandatory tells the versions before must upgrade, e.g. for a security fix.
+	Mandatory bool
+}
+
+// Manifest is a list of releases.
+type Manifest struct {
+	Releases []Release
+}
+
+type jsonManifest struct {
+	Releases []jsonRelease `json:"releases"`
+}
+
+type jsonRelease struct {
+	Version            string `json:"version"`
+	Channel            string `json:"channel"`
+	Date               string `json:"date"`
+	MinimumUpgradeFrom string `json:"minimum_upgrade_from"`
+	Mandatory          bool   `json:"mandatory"`
+}
+
+// ParseChannel returns the tag of a channel name, i.e. stable (or release), rc, beta or alpha.
+func ParseChannel(name string) (version.PreRelTag, error) {
+	switch name {
+	case "stable", "release":
+		return version.Release, nil
+	case "rc":
+		return version.ReleaseCandidate, nil
+	case "beta":
+		return version.Beta, nil
+	case "alpha":
+		return version.Alpha, nil
+	default:
+		return 0, fmt.Errorf("unknown channel %s", name)
+	}
+}
+
+// Load reads a manifest in JSON, where the versions are written in the layout.
+// The channel of a release is optional, and it is the tag of its version if absent.
+func Load(r io.Reader, layout *version.Layout) (*Manifest, error) {
+	var raw jsonManifest
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("manifest not readable: %w", err)
+	}
+	m := &Manifest{Releases: make([]Release, len(raw.Releases))}
+	for i, rr := range raw.Releases {
+		fail := func(err error) (*Manifest, error) {
+			return nil, fmt.Errorf("release %d in manifest: %w", i, err)
+		}
+		release := &m.Releases[i]
+		v, err := layout.Parse(rr.Version)
+		if err != nil {
+			return fail(err)
+		}
+		release.Version = v
+		release.Channel = v.PreRel
+		if rr.Channel != "" {
+			if release.Channel, err = ParseChannel(rr.Channel); err != nil {
+				return fail(err)
+			}
+		}
+		if rr.Date != "" {
+			if release.Date, err = time.Parse(DateLayout, rr.Date); err != nil {
+				return fail(err)
+			}
+		}
+		if rr.MinimumUpgradeFrom != "" {
+			if release.MinimumUpgradeFrom, err = layout.Parse(rr.MinimumUpgradeFrom); err != nil {
+				return fail(err)
+			}
+		}
+		release.Mandatory = rr.Mandatory
+	}
+	return m, nil
+}
+
+// LoadFile reads a manifest from a file, like Load.
+func LoadFile(name string, layout *version.Layout) (*Manifest, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f, layout)
+}
+
+// Fetch reads a manifest from a URL with the client, or http.DefaultClient if nil, like Load.
+func Fetch(ctx context.Context, client *http.Client, url string, layout *version.Layout) (*Manifest, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("manifest %s not fetched: %s", url, resp.Status)
+	}
+	return Load(resp.Body, layout)
+}
@@ -0,0 +1,196 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package updatecheck_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gsxab/go-version"
+	"github.com/gsxab/go-version/updatecheck"
+)
+
+var layout = version.MustCompile("5.4.3[-beta.1]")
+
+const manifest = `{"releases": [
+	{"version": "1.0.0", "date": "2023-01-10"},
+	{"version": "1.1.0", "channel": "stable", "date": "2023-03-01"},
+	{"version": "1.2.0", "date": "2023-06-01", "mandatory": true},
+	{"version": "2.0.0", "date": "2024-01-01", "minimum_upgrade_from": "1.2.0"},
+	{"version": "2.1.0-beta.1", "date": "2024-02-01"},
+	{"version": "2.1.0-rc.1", "channel": "beta", "date": "2024-03-01"}
+]}`
+
+func mustParse(t *testing.T, versionString string) *version.Version {
+	v, err := layout.Parse(versionString)
+	if err != nil {
+		t.Fatalf("unexpected error: %v; version: %v", err, versionString)
+	}
+	return v
+}
+
+func TestLoad(t *testing.T) {
+	m, err := updatecheck.Load(strings.NewReader(manifest), layout)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(m.Releases) != 6 {
+		t.Fatalf("release count expectation failed, expected: %v, actual: %v", 6, len(m.Releases))
+	}
+	r := m.Releases[3]
+	if layout.Format(r.Version) != "2.0.0" || r.Channel != version.Release || layout.Format(r.MinimumUpgradeFrom) != "1.2.0" ||
+		!r.Date.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || r.Mandatory {
+		t.Errorf("release expectation failed, actual: %+v", r)
+	}
+	if r := m.Releases[4]; r.Channel != version.Beta {
+		t.Errorf("channel expectation failed, expected: %v, actual: %v", version.Beta, r.Channel)
+	}
+	if r := m.Releases[5]; r.Channel != version.Beta {
+		t.Errorf("channel expectation failed, expected: %v, actual: %v", version.Beta, r.Channel)
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	cases := []struct {
+		Manifest string
+		Err      string
+	}{
+		{`{"releases": [`, "manifest not readable"},
+		{`{"releases": [{"version": "1.x"}]}`, "release 0 in manifest"},
+		{`{"releases": [{"version": "1.0.0"}, {"version": "1.1.0", "channel": "nightly"}]}`, "release 1 in manifest: unknown channel nightly"},
+		{`{"releases": [{"version": "1.0.0", "date": "2023-13-01"}]}`, "release 0 in manifest"},
+		{`{"releases": [{"version": "1.0.0", "minimum_upgrade_from": "0.x"}]}`, "release 0 in manifest"},
+	}
+
+	for _, c := range cases {
+		_, err := updatecheck.Load(strings.NewReader(c.Manifest), layout)
+		if err == nil || !strings.HasPrefix(err.Error(), c.Err) {
+			t.Errorf("error expectation failed, expected: %v, actual: %v; manifest: %v", c.Err, err, c.Manifest)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	m, err := updatecheck.Load(strings.NewReader(manifest), layout)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		Current   string
+		Channel   version.PreRelTag
+		Latest    string
+		Next      string
+		Mandatory bool
+	}{
+		{"2.0.0", version.Release, "", "", false},
+		{"2.0.0", version.Beta, "2.1.0-rc.1", "2.1.0-rc.1", false},
+		{"2.0.0", version.Alpha, "2.1.0-rc.1", "2.1.0-rc.1", false},
+		{"1.2.0", version.Release, "2.0.0", "2.0.0", false},
+		{"1.1.0", version.Release, "2.0.0", "1.2.0", true},
+		{"1.0.0", version.Beta, "2.1.0-rc.1", "2.1.0-rc.1", true},
+		{"2.1.0-beta.1", version.Beta, "2.1.0-rc.1", "2.1.0-rc.1", false},
+		{"2.1.0", version.Beta, "", "", false},
+	}
+
+	for _, c := range cases {
+		update, err := m.Check(layout, mustParse(t, c.Current), c.Channel)
+		if err != nil {
+			t.Errorf("unexpected error: %v; current: %v", err, c.Current)
+			continue
+		}
+		if c.Latest == "" {
+			if update != nil {
+				t.Errorf("update not expected, actual: %v; current: %v, channel: %v", layout.Format(update.Latest.Version), c.Current, c.Channel)
+			}
+			continue
+		}
+		if update == nil {
+			t.Errorf("update expected; current: %v, channel: %v", c.Current, c.Channel)
+			continue
+		}
+		actual := fmt.Sprintf("%s %s %v", layout.Format(update.Latest.Version), layout.Format(update.Next.Version), update.Mandatory)
+		expected := fmt.Sprintf("%s %s %v", c.Latest, c.Next, c.Mandatory)
+		if actual != expected {
+			t.Errorf("update expectation failed, expected: %v, actual: %v; current: %v, channel: %v", expected, actual, c.Current, c.Channel)
+		}
+	}
+}
+
+func TestCheckNoUpgradePath(t *testing.T) {
+	m, err := updatecheck.Load(strings.NewReader(`{"releases": [{"version": "2.0.0", "minimum_upgrade_from": "1.2.0"}]}`), layout)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = m.Check(layout, mustParse(t, "1.1.0"), version.Release)
+	var e *updatecheck.NoUpgradePathError
+	if !errors.As(err, &e) || err.Error() != "no upgrade path from 1.1.0 to 2.0.0" {
+		t.Errorf("error expectation failed, expected: %v, actual: %v", "no upgrade path from 1.1.0 to 2.0.0", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "updatecheck")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "manifest.json")
+	if err := ioutil.WriteFile(name, []byte(manifest), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m, err := updatecheck.LoadFile(name, layout)
+	if err != nil || len(m.Releases) != 6 {
+		t.Errorf("manifest expectation failed, actual: %v, error: %v", m, err)
+	}
+	if _, err := updatecheck.LoadFile(filepath.Join(dir, "missing.json"), layout); err == nil {
+		t.Errorf("error expected for a missing file")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/manifest.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, manifest)
+	}))
+	defer server.Close()
+
+	m, err := updatecheck.Fetch(context.Background(), server.Client(), server.URL+"/manifest.json", layout)
+	if err != nil || len(m.Releases) != 6 {
+		t.Errorf("manifest expectation failed, actual: %v, error: %v", m, err)
+	}
+	_, err = updatecheck.Fetch(context.Background(), nil, server.URL+"/missing.json", layout)
+	if err == nil || !strings.HasSuffix(err.Error(), "not fetched: 404 Not Found") {
+		t.Errorf("error expectation failed, expected: %v, actual: %v", "not fetched: 404 Not Found", err)
+	}
+}
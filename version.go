@@ -18,6 +18,8 @@
 
 package version
 
+import "fmt"
+
 type PreRelTag int64
 
 const (
@@ -27,6 +29,22 @@ const (
 	Release
 )
 
+// String returns the name of the tag, i.e. alpha, beta, rc or release.
+func (tag PreRelTag) String() string {
+	switch tag {
+	case Alpha:
+		return "alpha"
+	case Beta:
+		return "beta"
+	case ReleaseCandidate:
+		return "rc"
+	case Release:
+		return "release"
+	default:
+		return fmt.Sprintf("PreRelTag(%d)", int64(tag))
+	}
+}
+
 type Version struct {
 	Major  int64
 	Minor  int64
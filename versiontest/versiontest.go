@@ -0,0 +1,92 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package versiontest provides helpers to check layouts in tests.
+package versiontest
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/gsxab/go-version"
+)
+
+// DefaultSize is the greatest counter generated by Values.
+const DefaultSize = 100
+
+// Values returns a function generating arguments of type *version.Version for quick.Config.Values,
+// which are representable in the layout.
+func Values(l *version.Layout) func([]reflect.Value, *rand.Rand) {
+	return func(values []reflect.Value, r *rand.Rand) {
+		for i := range values {
+			values[i] = reflect.ValueOf(l.Generate(r, DefaultSize))
+		}
+	}
+}
+
+// CheckLayout checks that random versions representable in the layout are written and read back the same,
+// and the strings written are canonical, failing the test otherwise.
+// The config may be nil, and its Values is replaced.
+func CheckLayout(t testing.TB, layout string, config *quick.Config) {
+	t.Helper()
+	l, err := version.Compile(layout)
+	if err != nil {
+		t.Fatalf("layout not compiled: %s, %v", layout, err)
+		return
+	}
+	c := quick.Config{}
+	if config != nil {
+		c = *config
+	}
+	c.Values = Values(l)
+
+	check := func(v *version.Version) bool {
+		if err := l.CheckRoundTrip(v); err != nil {
+			t.Errorf("round trip failed: %v", err)
+			return false
+		}
+		if err := l.CheckRoundTripString(l.Format(v)); err != nil {
+			t.Errorf("round trip failed: %v", err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(check, &c); err != nil {
+		if _, ok := err.(*quick.CheckError); !ok {
+			t.Errorf("property check failed: %v", err)
+		}
+	}
+}
+
+// CheckStrings checks that the version strings are read in the layout, and written back canonically,
+// failing the test otherwise.
+func CheckStrings(t testing.TB, layout string, versionStrings ...string) {
+	t.Helper()
+	l, err := version.Compile(layout)
+	if err != nil {
+		t.Fatalf("layout not compiled: %s, %v", layout, err)
+		return
+	}
+	for _, s := range versionStrings {
+		if err := l.CheckRoundTripString(s); err != nil {
+			t.Errorf("round trip failed: %v", err)
+		}
+	}
+}
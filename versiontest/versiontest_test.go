@@ -0,0 +1,88 @@
+/*
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Copyright (c) 2023 Gsxab
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package versiontest_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/gsxab/go-version/versiontest"
+)
+
+// recorder records failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheckLayout(t *testing.T) {
+	layouts := []string{
+		"5.4",
+		"v5.4$.3$.1",
+		"5.4.3-beta.1",
+		"05.04.003",
+		"5.4[.3]-beta.1",
+		"5.4y",
+		"5.4.3z",
+		"YYYY.0M.0D$.1",
+		"YY.0W",
+		"5.4.3[o]",
+		"v{major}.{minor}[.{patch}]{-pre}.{build}",
+	}
+	for _, layout := range layouts {
+		versiontest.CheckLayout(t, layout, &quick.Config{Rand: rand.New(rand.NewSource(1))})
+	}
+}
+
+func TestCheckLayoutFailure(t *testing.T) {
+	// a release is written as `1.2.35` for a build of 5, read as a patch of 35
+	r := &recorder{}
+	versiontest.CheckLayout(r, "5.4.3b1", &quick.Config{Rand: rand.New(rand.NewSource(1))})
+	if len(r.errors) == 0 {
+		t.Errorf("ambiguous layout passed")
+	}
+
+	r = &recorder{}
+	versiontest.CheckLayout(r, "5.4[", nil)
+	if len(r.errors) == 0 {
+		t.Errorf("ill-formed layout passed")
+	}
+}
+
+func TestCheckStrings(t *testing.T) {
+	versiontest.CheckStrings(t, "[v]5.4$.3", "v1.2.0", "1.2", "1.2.3")
+
+	r := &recorder{}
+	versiontest.CheckStrings(r, "5.4", "1.x")
+	if len(r.errors) == 0 {
+		t.Errorf("ill-formed version string passed")
+	}
+}